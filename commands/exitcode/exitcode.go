@@ -0,0 +1,32 @@
+// Package exitcode records the failure of the commands, so that the cli exits with a non-zero status when one of them
+// fails. The application framework logs the error of a command but drops its exit code once the application stops.
+package exitcode
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/kiwiworks/rodent/command"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// Failure is the exit code of the cli once a command failed.
+const Failure = 1
+
+var code atomic.Int32
+
+// Do is command.Do recording the failure of impl, the exit code of the cli being then returned by Code.
+func Do(impl func(ctx context.Context) error) opt.Option[command.Command] {
+	return command.Do(func(ctx context.Context) error {
+		err := impl(ctx)
+		if err != nil {
+			code.Store(Failure)
+		}
+		return err
+	})
+}
+
+// Code returns the exit code of the cli, which is non-zero once a command failed.
+func Code() int {
+	return int(code.Load())
+}
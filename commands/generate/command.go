@@ -1,7 +1,46 @@
 package generate
 
-import "github.com/kiwiworks/rodent/command"
+import (
+	"context"
+
+	"github.com/kiwiworks/rodent-cli/commands/exitcode"
+	"github.com/kiwiworks/rodent-cli/commands/generate/project"
+	"github.com/kiwiworks/rodent/command"
+)
 
 func CommandGroup() *command.Command {
-	return command.New("generate", "g", "Generates new files")
+	var (
+		configFile = project.DefaultConfigFile
+		opts       project.Options
+	)
+	return command.New("generate", "g", "Generates new files, from every spec of the project file when run on its own",
+		exitcode.Do(func(ctx context.Context) error {
+			config, err := project.LoadConfig(configFile)
+			if err != nil {
+				return err
+			}
+			return project.Generate(ctx, config, opts)
+		}),
+		command.StringFlag(command.Flag{
+			Name:      "config",
+			Shorthand: "c",
+			Usage:     "project file listing the specs to generate code from",
+		}, &configFile),
+		command.StringsFlag(command.Flag{
+			Name:  "only",
+			Usage: "names of the specs of the project file to generate, all of them when empty",
+		}, &opts.Only),
+		command.BoolFlag(command.Flag{
+			Name:  "check",
+			Usage: "print the diff between the generated code and the one on disk instead of writing it, failing when they differ",
+		}, &opts.Check),
+		command.BoolFlag(command.Flag{
+			Name:  "dry-run",
+			Usage: "list the files that would be created, modified or deleted instead of writing them",
+		}, &opts.DryRun),
+		command.BoolFlag(command.Flag{
+			Name:  "diff",
+			Usage: "print the diff of the files listed by --dry-run, colorized when printed to a terminal",
+		}, &opts.Diff),
+	)
 }
@@ -5,20 +5,25 @@ import (
 	"net/url"
 	"path"
 
+	"github.com/kiwiworks/rodent-cli/commands/exitcode"
 	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
 	"github.com/kiwiworks/rodent/command"
 	"github.com/kiwiworks/rodent/errors"
 )
 
 func GenerateOpenAPIClient() *command.Command {
+	return GenerateCommand("generate.openapi.client", "Generate OAS3 client", generator.DefaultFlags())
+}
+
+// GenerateCommand returns the command generating code from a spec, the target of the generator being set by flags.
+func GenerateCommand(name, short string, flags generator.Flags) *command.Command {
 	var (
 		filename string
 		fileUrl  string
 	)
-	flags := generator.DefaultFlags()
 
-	return command.New("generate.openapi.client", "Generate OAS3 client", "todo",
-		command.Do(func(ctx context.Context) error {
+	return command.New(name, short, "todo",
+		exitcode.Do(func(ctx context.Context) error {
 			if filename == "" && fileUrl == "" {
 				return errors.Newf("either filename or url must be provided")
 			}
@@ -31,14 +36,14 @@ func GenerateOpenAPIClient() *command.Command {
 					Scheme: "file",
 					Path:   filename,
 				}
-				return generator.Generate(ctx, u, flags)
+				return generate(ctx, u, flags)
 			}
 			if fileUrl != "" {
 				u, err := url.Parse(fileUrl)
 				if err != nil {
 					return err
 				}
-				return generator.Generate(ctx, *u, flags)
+				return generate(ctx, *u, flags)
 			}
 			return errors.Newf("not implemented")
 		}),
@@ -68,5 +73,57 @@ func GenerateOpenAPIClient() *command.Command {
 			Required:  false,
 			Usage:     "generate module, if set to false, will generate a simple package without an associated go.mod file",
 		}, &flags.GenerateModule),
+		command.StringFlag(command.Flag{
+			Name:  "module-path",
+			Usage: "path of the generated module, defaults to the sanitized title of the spec",
+		}, &flags.ModuleName),
+		command.StringFlag(command.Flag{
+			Name:  "package",
+			Usage: "name of the generated package, defaults to the target (client, server or mock)",
+		}, &flags.PackageName),
+		command.StringFlag(command.Flag{
+			Name:  "dtos-package",
+			Usage: "name of the package holding the types of the schemas",
+		}, &flags.DTOsPackageName),
+		command.StringFlag(command.Flag{
+			Name:  "go-version",
+			Usage: "version of the go directive of the generated go.mod, defaults to the version of the local toolchain",
+		}, &flags.GoVersion),
+		command.BoolFlag(command.Flag{
+			Name:  "offline",
+			Usage: "generate a complete go.mod and go.sum from the local module cache, without any network access",
+		}, &flags.Offline),
+		command.StringsFlag(command.Flag{
+			Name:  "allow-host",
+			Usage: "hosts remote references can be fetched from, the host of --url is always allowed",
+		}, &flags.AllowedHosts),
+		command.BoolFlag(command.Flag{
+			Name:  "check",
+			Usage: "print the diff between the generated code and the one in the output directory instead of writing it, failing when they differ",
+		}, &flags.Check),
+		command.BoolFlag(command.Flag{
+			Name:  "dry-run",
+			Usage: "list the files that would be created, modified or deleted instead of writing them",
+		}, &flags.DryRun),
+		command.BoolFlag(command.Flag{
+			Name:  "diff",
+			Usage: "print the diff of the files listed by --dry-run, colorized when printed to a terminal",
+		}, &flags.Diff),
+		command.StringFlag(command.Flag{
+			Name:  "ordering",
+			Usage: "order of the generated types, properties and methods, either sorted by name or in spec order",
+		}, &flags.Ordering),
+		command.StringFlag(command.Flag{
+			Name:  "grouping",
+			Usage: "how the client operations are split into sub-clients, either none, tag (first tag), path (first path segment) or x-group (extension)",
+		}, &flags.Grouping),
+		command.StringFlag(command.Flag{
+			Name:  "optional-style",
+			Usage: "how optional and nullable properties are generated, either pointer or nullable (generic Optional[T]/Nullable[T] types keeping track of absent, null and set values)",
+		}, &flags.OptionalStyle),
 	)
 }
+
+func generate(ctx context.Context, uri url.URL, flags generator.Flags) error {
+	return generator.Generate(ctx, uri, flags)
+}
@@ -2,8 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"path"
-	"path/filepath"
 	"strings"
 
 	"github.com/chanced/caps"
@@ -23,15 +21,21 @@ const (
 	errPackage = "github.com/kiwiworks/rodent/errors"
 )
 
-func (g *Generator) generateClient(document v3.Document, f *jen.File) error {
+func (g *Generator) generateClient(document v3.Document, f *jen.File, groups []*clientGroup) error {
 	f.Type().Id("Client").StructFunc(func(group *jen.Group) {
 		group.Op("*").Qual(sdkPackage, "Client")
+		for _, sub := range groups[1:] {
+			group.Id(sub.field).Op("*").Id(sub.typeName)
+		}
 	})
-
-	g.imports = append(g.imports, Import{
-		Package: "github.com/kiwiworks/rodent",
-		Version: "latest",
-	})
+	for _, sub := range groups[1:] {
+		sub.file.Commentf("%s performs the operations of the %s group, it is reached through the %s field of the Client.", sub.typeName, sub.field, sub.field)
+		sub.file.Type().Id(sub.typeName).Struct(jen.Op("*").Qual(sdkPackage, "Client"))
+		f.Commentf("%s returns the client of the %s group as a %s.", sub.accessorName(), sub.field, sub.interfaceName())
+		f.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(sub.accessorName()).Params().Id(sub.interfaceName()).Block(
+			jen.Return(jen.Id("c").Dot(sub.field)),
+		)
+	}
 
 	f.Comment("NewClient creates a new client from the given string endpoint, and optional options.")
 	f.Func().
@@ -49,6 +53,17 @@ func (g *Generator) generateClient(document v3.Document, f *jen.File) error {
 					group.Id("endpoint").Op("=").Lit(document.Servers[0].URL)
 				})
 			}
+			group.Comment("exploded query parameters are encoded first, so that user defined interceptors see the final URL,")
+			group.Comment("while responses are captured last, so that user defined interceptors still get to see them")
+			group.Id("opts").Op("=").Append(
+				append(
+					slices.Of[jen.Code](jen.Index().Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config")).Values(
+						jen.Qual(sdkPackage, "AddRequestInterceptor").Call(jen.Id("encodeExplodedQuery")),
+					)),
+					jen.Id("opts").Op("..."),
+				)...,
+			)
+			group.Id("opts").Op("=").Append(jen.Id("opts"), jen.Qual(sdkPackage, "AddResponseInterceptor").Call(jen.Id("captureResponseError")))
 			group.List(jen.Id("c"), jen.Id("err")).Op(":=").
 				Qual(sdkPackage, "New").
 				Call(jen.Id("endpoint"), jen.Id("opts").Op("..."))
@@ -61,29 +76,18 @@ func (g *Generator) generateClient(document v3.Document, f *jen.File) error {
 					))
 				})
 			group.ReturnFunc(func(group *jen.Group) {
-				group.Op("&").Id("Client").Values(jen.Dict{
-					jen.Id("Client"): jen.Id("c"),
-				})
+				group.Op("&").Id("Client").Values(jen.DictFunc(func(dict jen.Dict) {
+					dict[jen.Id("Client")] = jen.Id("c")
+					for _, sub := range groups[1:] {
+						dict[jen.Id(sub.field)] = jen.Op("&").Id(sub.typeName).Values(jen.Dict{jen.Id("Client"): jen.Id("c")})
+					}
+				}))
 				group.Nil()
 			})
 		})
 	return nil
 }
 
-func methodNameFromOperation(method string, operation *v3.Operation) string {
-	operationId := operation.OperationId
-	if operationId == "" && len(operation.Tags) > 0 {
-		operationId = caps.ToCamel(method) + caps.ToCamel(operation.Tags[0])
-	}
-	fragments := strings.FieldsFunc(operationId, func(r rune) bool {
-		return r == '-' || r == '_' || r == '.'
-	})
-	fragments = slices.Map(fragments, func(in string) string {
-		return caps.ToCamel(in)
-	})
-	return strings.Join(fragments, "")
-}
-
 func schemaByResponseContent(response *v3.Response, content string) (*base.SchemaProxy, error) {
 	if mediaType := response.Content.Value(content); mediaType != nil {
 		return mediaType.Schema, nil
@@ -119,28 +123,32 @@ type (
 	pathParamsCode         struct {
 		codeDecorator pathParamCodeDecorator
 		params        pathParamMethodParams
+		// names holds the names of the params, in order.
+		names []string
 	}
 )
 
-func (g *Generator) generatePathParamsCode(path string, operation *v3.Operation) pathParamsCode {
+func (g *Generator) generatePathParamsCode(path string, operationParams []*v3.Parameter) pathParamsCode {
 	fragments := strings.Split(path, "/")
 	orderedParams := make([]string, 0)
 	finalFragments := make([]string, len(fragments))
 	params := make(pathParamMethodParams, 0)
+	names := make([]string, 0)
 	for idx, fragment := range fragments {
 		finalFragment := fragment
 		if strings.HasPrefix(fragment, "{") && strings.HasSuffix(fragment, "}") {
 			fragmentParam := strings.Trim(caps.ToLowerCamel(fragment), "{}")
-			orderedParams = append(orderedParams, fragmentParam)
+			orderedParams = append(orderedParams, goParamName(fragmentParam))
 			finalFragment = "%s"
-			for _, opParam := range operation.Parameters {
+			for _, opParam := range operationParams {
 				if opParam.In != "path" {
 					continue
 				}
 				if caps.ToLowerCamel(opParam.Name) != fragmentParam {
 					continue
 				}
-				params = append(params, jen.Id(fragmentParam).String())
+				params = append(params, jen.Id(goParamName(fragmentParam)).String())
+				names = append(names, goParamName(fragmentParam))
 			}
 		}
 		finalFragments[idx] = finalFragment
@@ -151,32 +159,143 @@ func (g *Generator) generatePathParamsCode(path string, operation *v3.Operation)
 			group.Id("path").Op(":=").Qual("fmt", "Sprintf").CallFunc(func(group *jen.Group) {
 				group.Lit(path)
 				for _, param := range orderedParams {
-					group.Id(caps.ToLowerCamel(param))
+					group.Id(param)
 				}
 			})
 		},
 		params: params,
+		names:  names,
 	}
 }
 
-func (g *Generator) generateClientMethod(f *jen.File, method, apiPath string, operation *v3.Operation) error {
-	if operation == nil {
+type requestBodyCode struct {
+	codeDecorator func(group *jen.Group)
+	param         jen.Code
+}
+
+// requestBodyOption maps the supported request content types to the sdk option serializing them.
+var requestBodyOption = []struct {
+	contentType string
+	option      string
+}{
+	{contentType: "application/json", option: "WithJsonBody"},
+	{contentType: formContentType, option: "WithFormBody"},
+}
+
+// formContentType is the content type of the request bodies sent as url encoded forms.
+const formContentType = "application/x-www-form-urlencoded"
+
+// collectFormBodies records the schemas of the request bodies sent as url encoded forms, whose structs are given
+// `url` tags as go-querystring ignores the `json` ones.
+func (g *Generator) collectFormBodies(document v3.Document) error {
+	for _, apiPath := range orderedKeys(OrderingSorted, document.Paths.PathItems) {
+		for _, op := range pathOperations(document.Paths.PathItems.Value(apiPath)) {
+			body := op.operation.RequestBody
+			if body == nil || body.Content == nil {
+				continue
+			}
+			if mediaType := body.Content.Value(formContentType); mediaType != nil && mediaType.Schema != nil {
+				if err := g.collectFormSchema(mediaType.Schema); err != nil {
+					return errors.Wrapf(err, "invalid form body of %s %s", op.method, apiPath)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectFormSchema records the schema of a form body, along with the ones of its properties which are encoded as
+// nested forms.
+func (g *Generator) collectFormSchema(proxy *base.SchemaProxy) error {
+	schema, err := proxy.BuildSchema()
+	if err != nil {
+		return errors.Wrapf(err, "invalid schema %s", proxy.GetReference())
+	}
+	node := schema.GoLow().RootNode
+	if node == nil || g.formSchemas[node] {
 		return nil
 	}
+	g.formSchemas[node] = true
+	properties, err := g.collectProperties(schema)
+	if err != nil {
+		return err
+	}
+	for _, p := range properties {
+		if err := g.collectFormSchema(p.proxy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isFormSchema reports whether the schema is encoded as a url encoded form.
+func (g *Generator) isFormSchema(schema *base.Schema) bool {
+	return schema.GoLow() != nil && g.formSchemas[schema.GoLow().RootNode]
+}
+
+// generateRequestBody returns the `body` parameter of the client method and the code attaching it to the request,
+// or nil if the operation has no request body with a supported content type.
+func (g *Generator) generateRequestBody(methodName, method string, operation *v3.Operation) (*requestBodyCode, error) {
+	if operation.RequestBody == nil || operation.RequestBody.Content == nil {
+		return nil, nil
+	}
+	for _, candidate := range requestBodyOption {
+		mediaType := operation.RequestBody.Content.Value(candidate.contentType)
+		if mediaType == nil || mediaType.Schema == nil {
+			continue
+		}
+		schema, err := mediaType.Schema.BuildSchema()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid request body schema for %s %s", method, operation.OperationId)
+		}
+		required := operation.RequestBody.Required != nil && *operation.RequestBody.Required
+		// slices are already nil-able, everything else must be a pointer to be optional
+		nilable := slices.Contains(schema.Type, "array")
+		param := jen.Id("body")
+		if !required && !nilable {
+			param.Op("*")
+		}
+		if err := g.oas3TypeToGoType(param, mediaType.Schema, schema, methodName+"Request"); err != nil {
+			return nil, errors.Wrapf(err, "invalid request body type for %s %s", method, operation.OperationId)
+		}
+		option := candidate.option
+		return &requestBodyCode{
+			codeDecorator: func(group *jen.Group) {
+				withBody := jen.Qual(sdkPackage, option).Call(jen.Id("body"))
+				if required {
+					group.Id("bodyOpts").Op(":=").Index().Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Request")).
+						Values(withBody)
+					return
+				}
+				group.Var().Id("bodyOpts").Index().Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Request"))
+				group.If(jen.Id("body").Op("!=").Nil()).BlockFunc(func(group *jen.Group) {
+					group.Id("bodyOpts").Op("=").Append(jen.Id("bodyOpts"), withBody)
+				})
+			},
+			param: param,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (g *Generator) generateClientMethod(group *clientGroup, method, apiPath string, pathItem *v3.PathItem, operation *v3.Operation) error {
+	if operation == nil || !g.isOperationSelected(apiPath, operation) {
+		return nil
+	}
+	f := group.file
 
 	log := logger.New().With(props.HttpMethod(method), props.HttpPath(apiPath))
-	methodName := methodNameFromOperation(method, operation)
+	methodName := g.methodName(operation)
 
 	params := slices.Of[jen.Code](jen.Id("ctx").Qual("context", "Context"))
 
-	dtoPackage := path.Join(g.moduleName, "dtos")
-	if operation.RequestBody != nil {
-		request := operation.RequestBody.Content.Value("application/json")
-		if request == nil && slices.Contains(slices.Of("POST", "PUT", "PATCH"), strings.ToUpper(method)) {
-			log.Warn("no request body found for operation with content type application/json")
-			return nil
-		}
-		//params = append(params, jen.Id("req").Op("*").Qual(dtoPackage, filepath.Base(request.Schema.GetReference())))
+	body, err := g.generateRequestBody(methodName, method, operation)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate request body for %s", apiPath)
+	}
+	if body == nil && operation.RequestBody != nil && slices.Contains(slices.Of("POST", "PUT", "PATCH"), strings.ToUpper(method)) {
+		log.Warn("no request body found for operation with a supported content type")
+		return nil
 	}
 
 	response, err := operationResponse(operation, "application/json")
@@ -188,44 +307,69 @@ func (g *Generator) generateClientMethod(f *jen.File, method, apiPath string, op
 		return nil
 	}
 
-	var result jen.Code
-	var executeResult jen.Code
-	responseType := filepath.Base(response.GetReference())
-	schema, err := response.BuildSchema()
-	if err != nil {
-		return errors.Wrapf(err, "failed to build response schema for %s", apiPath)
+	executeResult := jen.Null()
+	if err := g.schemaProxyToGoType(executeResult, response, methodName+"Response"); err != nil {
+		return errors.Wrapf(err, "failed to generate client method for %s, invalid response type", apiPath)
 	}
-	if schema.Items != nil && schema.Items.IsA() {
-		result = jen.Id("response").Op("*").List(
-			jen.Index().Qual(dtoPackage, filepath.Base(schema.Items.A.GetReference())),
-		)
-		executeResult = jen.List(jen.Index().Qual(dtoPackage, filepath.Base(schema.Items.A.GetReference())))
-	} else if responseType != "" && schema.Type[0] == "object" {
-		result = jen.Id("response").Op("*").Qual(dtoPackage, responseType)
-		executeResult = jen.Qual(dtoPackage, responseType)
-	} else {
-		stmt := jen.Id("response").Op("*")
-		if err := oas3TypeToGoType(stmt, response, schema); err != nil {
-			return errors.Wrapf(err, "failed to generate client method for %s, invalid response type", apiPath)
-		}
-		result = stmt
-		stmt = jen.Op("")
-		if err := oas3TypeToGoType(stmt, response, schema); err != nil {
-			return errors.Wrapf(err, "failed to generate client method for %s, invalid response type", apiPath)
-		}
-		executeResult = stmt
+	result := jen.Id("response").Op("*").Add(executeResult.Clone())
+
+	errorDecoder, err := g.generateOperationErrors(f, methodName, method, apiPath, operation)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate error responses for %s", apiPath)
 	}
 
-	generated := g.generatePathParamsCode(apiPath, operation)
+	operationParams := operationParameters(pathItem, operation)
+	generated := g.generatePathParamsCode(apiPath, operationParams)
 	params = append(params, generated.params...)
+	args := slices.Map(append(slices.Of("ctx"), generated.names...), func(in string) jen.Code { return jen.Id(in) })
+	if body != nil {
+		params = append(params, body.param)
+		args = append(args, jen.Id("body"))
+	}
+	paramsStruct, err := g.generateParamsStruct(f, methodName, operationParams)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate parameters for %s", apiPath)
+	}
+	if paramsStruct != nil {
+		params = append(params, paramsStruct.param)
+		args = append(args, jen.Id("params"))
+	}
 	params = append(params, jen.Id("opts").Op("...").Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Request")))
+	group.methods = append(group.methods, clientMethod{
+		name:    methodName,
+		summary: fmt.Sprintf("%s performs the %s %s operation.", methodName, method, apiPath),
+		params:  params,
+		args:    args,
+		result:  jen.Op("*").Add(executeResult.Clone()),
+	})
+
+	var security []string
+	if g.hasSecurity {
+		security = operationSecuritySchemes(g.model.Model, operation)
+	}
 
 	f.Commentf("%s performs the %s %s operation.\n%s", methodName, method, apiPath, operation.Description)
-	f.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(methodName).
+	f.Func().Params(jen.Id("c").Op("*").Id(group.typeName)).Id(methodName).
 		Params(params...).
 		Parens(jen.List(result, jen.Id("err").Error())).
 		BlockFunc(func(group *jen.Group) {
+			if security != nil {
+				group.Id("ctx").Op("=").Id("withSecurity").Call(append(
+					slices.Of[jen.Code](jen.Id("ctx")),
+					slices.Map(security, func(in string) jen.Code { return jen.Lit(in) })...,
+				)...)
+			}
 			generated.codeDecorator(group)
+			if body != nil {
+				body.codeDecorator(group)
+				group.Id("opts").Op("=").Append(jen.Id("bodyOpts"), jen.Id("opts").Op("..."))
+			}
+			if paramsStruct != nil {
+				group.Id("opts").Op("=").Append(jen.Id("params").Dot("requestOptions").Call(), jen.Id("opts").Op("..."))
+				if paramsStruct.exploded {
+					group.Id("ctx").Op("=").Id("withExplodedQuery").Call(jen.Id("ctx"), jen.Id("params").Dot("explodedQuery").Call())
+				}
+			}
 			group.Id("request").Op(":=").Id("c").Dot("Request").CallFunc(func(group *jen.Group) {
 				group.Lit(method)
 				group.Id("path")
@@ -239,7 +383,11 @@ func (g *Generator) generateClientMethod(f *jen.File, method, apiPath string, op
 				})
 			group.If(jen.Err().Op("!=").Nil()).BlockFunc(func(group *jen.Group) {
 				group.Return(jen.Id("response"), jen.Qual(errPackage, "Wrapf").CallFunc(func(group *jen.Group) {
-					group.Id("err")
+					if errorDecoder != "" {
+						group.Id(errorDecoder).Call(jen.Id("err"))
+					} else {
+						group.Id("err")
+					}
 					group.Lit(fmt.Sprintf("failed to execute %s %s operation", method, apiPath))
 				}))
 			})
@@ -248,39 +396,52 @@ func (g *Generator) generateClientMethod(f *jen.File, method, apiPath string, op
 	return nil
 }
 
+// pathOperation is an operation of a path item, along with its http method.
+type pathOperation struct {
+	method    string
+	operation *v3.Operation
+}
+
+// pathOperations lists the operations of the path item, in a fixed order of http methods.
+func pathOperations(pathItem *v3.PathItem) []pathOperation {
+	return slices.Filter([]pathOperation{
+		{method: "GET", operation: pathItem.Get},
+		{method: "POST", operation: pathItem.Post},
+		{method: "PUT", operation: pathItem.Put},
+		{method: "PATCH", operation: pathItem.Patch},
+		{method: "DELETE", operation: pathItem.Delete},
+		{method: "HEAD", operation: pathItem.Head},
+		{method: "OPTIONS", operation: pathItem.Options},
+		{method: "TRACE", operation: pathItem.Trace},
+	}, func(in pathOperation) bool { return in.operation != nil })
+}
+
 func (g *Generator) generateClientPackage(document v3.Document) error {
-	f := g.generateFile("", "client")
-	if err := g.generateClient(document, f); err != nil {
+	f := g.generatePackageFile("", g.flags.PackageName, "client")
+	groups, byOperation := g.clientGroups(document, f)
+	if err := g.generateClient(document, f, groups); err != nil {
 		return errors.Wrapf(err, "failed to generate client")
 	}
+	g.generateParamsHelpers()
+	g.generateResponseError()
+	g.hasSecurity = g.generateSecurity(document)
 
-	for apiPath := range document.Paths.PathItems.KeysFromNewest() {
+	for _, apiPath := range orderedKeys(g.flags.Ordering, document.Paths.PathItems) {
 		pathItem := document.Paths.PathItems.Value(apiPath)
-		if err := g.generateClientMethod(f, "GET", apiPath, pathItem.Get); err != nil {
-			return errors.Wrapf(err, "failed to generate client GET method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "POST", apiPath, pathItem.Post); err != nil {
-			return errors.Wrapf(err, "failed to generate client POST method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "PUT", apiPath, pathItem.Put); err != nil {
-			return errors.Wrapf(err, "failed to generate client PUT method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "PATCH", apiPath, pathItem.Patch); err != nil {
-			return errors.Wrapf(err, "failed to generate client PATCH method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "DELETE", apiPath, pathItem.Delete); err != nil {
-			return errors.Wrapf(err, "failed to generate client DELETE method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "HEAD", apiPath, pathItem.Head); err != nil {
-			return errors.Wrapf(err, "failed to generate client HEAD method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "OPTIONS", apiPath, pathItem.Options); err != nil {
-			return errors.Wrapf(err, "failed to generate client OPTIONS method for %s", apiPath)
-		}
-		if err := g.generateClientMethod(f, "TRACE", apiPath, pathItem.Trace); err != nil {
-			return errors.Wrapf(err, "failed to generate client TRACE method for %s", apiPath)
+		for _, op := range pathOperations(pathItem) {
+			group, selected := byOperation[op.operation]
+			if !selected {
+				continue
+			}
+			if err := g.generateClientMethod(group, op.method, apiPath, pathItem, op.operation); err != nil {
+				return errors.Wrapf(err, "failed to generate client %s method for %s", op.method, apiPath)
+			}
 		}
 	}
+	for _, group := range groups {
+		g.generateClientInterface(group, groups[1:])
+	}
+	g.generateClientMock(groups)
 
 	return nil
 }
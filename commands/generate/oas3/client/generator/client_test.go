@@ -0,0 +1,19 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestClientGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "forms", spec: "forms"},
+	})
+}
+
+func TestFormBodies(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "forms", spec: "forms"}, "forms")
+}
+
+func TestMockClient(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "petstore", spec: "petstore"}, "clientmock")
+}
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// clientMethod is the signature of a generated client method, shared by the client interface and its mock.
+type clientMethod struct {
+	name    string
+	summary string
+	params  []jen.Code
+	// args holds the parameters forwarded to the mocked implementation, the variadic options excluded.
+	args   []jen.Code
+	result jen.Code
+}
+
+// signature returns the parameters and results of the method, as expected by jen.Params and jen.Parens.
+func (m clientMethod) signature() ([]jen.Code, jen.Code) {
+	return m.params, jen.List(m.result, jen.Error())
+}
+
+// generateClientInterface emits the interface implemented by the client of the group and its mock, so that consumers
+// can substitute it in their unit tests. The interface of the root group exposes the sub-clients through accessors.
+func (g *Generator) generateClientInterface(group *clientGroup, subGroups []*clientGroup) {
+	f := group.file
+	f.Commentf("%s lists the operations of the %s, it is implemented by %s and %s.", group.interfaceName(), group.typeName, group.typeName, group.mockName())
+	f.Type().Id(group.interfaceName()).InterfaceFunc(func(iface *jen.Group) {
+		for _, method := range group.methods {
+			params, results := method.signature()
+			iface.Comment(method.summary)
+			iface.Id(method.name).Params(params...).Parens(results)
+		}
+		if group.field != "" {
+			return
+		}
+		for _, sub := range subGroups {
+			iface.Commentf("%s returns the client of the %s group.", sub.accessorName(), sub.field)
+			iface.Id(sub.accessorName()).Params().Id(sub.interfaceName())
+		}
+	})
+	f.Var().Id("_").Id(group.interfaceName()).Op("=").Parens(jen.Op("*").Id(group.typeName)).Parens(jen.Nil())
+}
+
+// generateClientMock emits the mocks of the clients of every group, which record their calls and delegate them to
+// programmable functions. The mock of the root group holds the ones of the sub-clients.
+func (g *Generator) generateClientMock(groups []*clientGroup) {
+	f := g.generatePackageFile("", g.flags.PackageName, "client_mock")
+	g.generateMockRecorder(f)
+	for _, group := range groups {
+		mockFile := f
+		if group.field != "" {
+			mockFile = g.generatePackageFile("", g.flags.PackageName, caps.ToSnake(group.field)+"_client_mock")
+		}
+		g.generateGroupMock(mockFile, group, groups[1:])
+	}
+}
+
+// generateMockRecorder emits `MockCall` and the `mockRecorder` embedded by the mocks to record their calls.
+func (g *Generator) generateMockRecorder(f *jen.File) {
+	f.Comment("MockCall is a call recorded by a mock client.")
+	f.Type().Id("MockCall").Struct(
+		jen.Comment("Method is the name of the called method."),
+		jen.Id("Method").String(),
+		jen.Comment("Args holds the arguments of the call, in order, the variadic options being passed as a slice."),
+		jen.Id("Args").Index().Any(),
+	)
+
+	f.Comment("mockRecorder records the calls of a mock client.")
+	f.Type().Id("mockRecorder").Struct(
+		jen.Id("mu").Qual("sync", "Mutex"),
+		jen.Id("calls").Index().Id("MockCall"),
+	)
+
+	f.Comment("Calls returns the calls recorded so far, in order.")
+	f.Func().Params(jen.Id("m").Op("*").Id("mockRecorder")).Id("Calls").Params().Index().Id("MockCall").Block(
+		jen.Id("m").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("m").Dot("mu").Dot("Unlock").Call(),
+		jen.Return(jen.Qual("slices", "Clone").Call(jen.Id("m").Dot("calls"))),
+	)
+
+	f.Comment("CallsTo returns the calls of the named method recorded so far, in order.")
+	f.Func().Params(jen.Id("m").Op("*").Id("mockRecorder")).Id("CallsTo").Params(jen.Id("method").String()).Index().Id("MockCall").Block(
+		jen.Id("m").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("m").Dot("mu").Dot("Unlock").Call(),
+		jen.Var().Id("calls").Index().Id("MockCall"),
+		jen.For(jen.List(jen.Id("_"), jen.Id("call")).Op(":=").Range().Id("m").Dot("calls")).Block(
+			jen.If(jen.Id("call").Dot("Method").Op("==").Id("method")).Block(
+				jen.Id("calls").Op("=").Append(jen.Id("calls"), jen.Id("call")),
+			),
+		),
+		jen.Return(jen.Id("calls")),
+	)
+
+	f.Comment("Reset forgets the recorded calls, the programmed functions are left untouched.")
+	f.Func().Params(jen.Id("m").Op("*").Id("mockRecorder")).Id("Reset").Params().Block(
+		jen.Id("m").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("m").Dot("mu").Dot("Unlock").Call(),
+		jen.Id("m").Dot("calls").Op("=").Nil(),
+	)
+
+	f.Comment("record appends a call to the recorded ones.")
+	f.Func().Params(jen.Id("m").Op("*").Id("mockRecorder")).Id("record").Params(jen.Id("method").String(), jen.Id("args").Op("...").Any()).Block(
+		jen.Id("m").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("m").Dot("mu").Dot("Unlock").Call(),
+		jen.Id("m").Dot("calls").Op("=").Append(jen.Id("m").Dot("calls"), jen.Id("MockCall").Values(jen.Dict{
+			jen.Id("Method"): jen.Id("method"),
+			jen.Id("Args"):   jen.Id("args"),
+		})),
+	)
+}
+
+// generateGroupMock emits the mock of the client of the group, along with the fields holding the mocks of the
+// sub-clients for the root group.
+func (g *Generator) generateGroupMock(f *jen.File, group *clientGroup, subGroups []*clientGroup) {
+	mockName := group.mockName()
+	isRoot := group.field == ""
+
+	f.Commentf("%s is a %s implementation for unit tests. Each of its methods records the call, then", mockName, group.interfaceName())
+	f.Comment("delegates it to the function field named after the method, suffixed by Func. Calling a method whose function")
+	f.Comment("is unset returns an error. The recorded calls are listed by Calls and CallsTo.")
+	if isRoot && len(subGroups) > 0 {
+		f.Comment("The mocks of the sub-clients are allocated by NewMockClient, or by their accessor on a zero MockClient,")
+		f.Comment("which must then be called before programming them through their field.")
+	}
+	f.Type().Id(mockName).StructFunc(func(fields *jen.Group) {
+		fields.Id("mockRecorder")
+		fields.Line()
+		for _, method := range group.methods {
+			params, results := method.signature()
+			fields.Id(method.name + "Func").Func().Params(params...).Parens(results)
+		}
+		if isRoot && len(subGroups) > 0 {
+			fields.Line()
+			for _, sub := range subGroups {
+				fields.Id(sub.field).Op("*").Id(sub.mockName())
+			}
+		}
+	})
+	f.Var().Id("_").Id(group.interfaceName()).Op("=").Parens(jen.Op("*").Id(mockName)).Parens(jen.Nil())
+
+	if isRoot && len(subGroups) > 0 {
+		f.Comment("NewMockClient returns a MockClient along with the mocks of its sub-clients.")
+		f.Func().Id("NewMockClient").Params().Op("*").Id(mockName).Block(
+			jen.Return(jen.Op("&").Id(mockName).Values(jen.DictFunc(func(dict jen.Dict) {
+				for _, sub := range subGroups {
+					dict[jen.Id(sub.field)] = jen.Op("&").Id(sub.mockName()).Values()
+				}
+			}))),
+		)
+	}
+
+	if isRoot {
+		for _, sub := range subGroups {
+			field := jen.Id("m").Dot(sub.field)
+			f.Line()
+			f.Commentf("%s returns the mock of the %s group, allocating it if needed.", sub.accessorName(), sub.field)
+			f.Func().Params(jen.Id("m").Op("*").Id(mockName)).Id(sub.accessorName()).Params().Id(sub.interfaceName()).Block(
+				jen.Id("m").Dot("mu").Dot("Lock").Call(),
+				jen.Defer().Id("m").Dot("mu").Dot("Unlock").Call(),
+				jen.If(field.Clone().Op("==").Nil()).Block(
+					field.Clone().Op("=").Op("&").Id(sub.mockName()).Values(),
+				),
+				jen.Return(field.Clone()),
+			)
+		}
+	}
+
+	for _, method := range group.methods {
+		params, results := method.signature()
+		fn := jen.Id("m").Dot(method.name + "Func")
+		recordArgs := append(append(slices.Of[jen.Code](jen.Lit(method.name)), method.args...), jen.Id("opts"))
+		callArgs := append(append(make([]jen.Code, 0, len(method.args)+1), method.args...), jen.Id("opts").Op("..."))
+		f.Line()
+		f.Comment(method.summary)
+		f.Func().Params(jen.Id("m").Op("*").Id(mockName)).Id(method.name).Params(params...).Parens(results).Block(
+			jen.Id("m").Dot("record").Call(recordArgs...),
+			jen.If(fn.Clone().Op("==").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Qual(errPackage, "Newf").Call(
+					jen.Lit(mockName+"."+method.name+" called without "+method.name+"Func being set"),
+				)),
+			),
+			jen.Return(fn.Clone().Call(callArgs...)),
+		)
+	}
+}
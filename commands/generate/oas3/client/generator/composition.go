@@ -0,0 +1,337 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+type property struct {
+	name     string
+	proxy    *base.SchemaProxy
+	required bool
+	// owner is the type of the component schema the property is inherited from through `allOf`, if any.
+	owner string
+}
+
+// collectProperties returns the properties of the schema, flattening the ones of its `allOf` members into it. With
+// JSON Schema 2020-12, the properties of the `then` and `else` branches of a conditional are added as optional ones.
+func (g *Generator) collectProperties(schema *base.Schema) ([]property, error) {
+	properties := make([]property, 0)
+	indexes := make(map[string]int)
+	add := func(p property) {
+		if idx, exists := indexes[p.name]; exists {
+			properties[idx].required = properties[idx].required || p.required
+			return
+		}
+		indexes[p.name] = len(properties)
+		properties = append(properties, p)
+	}
+	for _, member := range schema.AllOf {
+		memberSchema, err := member.BuildSchema()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid allOf member %s", member.GetReference())
+		}
+		memberProperties, err := g.collectProperties(memberSchema)
+		if err != nil {
+			return nil, err
+		}
+		owner := ""
+		if member.IsReference() && g.isComponentRef(member) {
+			owner = g.componentTypeName(refName(member.GetReference()))
+		}
+		for _, p := range memberProperties {
+			p.required = p.required || slices.Contains(schema.Required, p.name)
+			if p.owner == "" {
+				p.owner = owner
+			}
+			add(p)
+		}
+	}
+	if schema.Properties != nil {
+		for _, name := range orderedKeys(g.flags.Ordering, schema.Properties) {
+			if name == "$schema" {
+				continue
+			}
+			add(property{
+				name:     name,
+				proxy:    schema.Properties.Value(name),
+				required: slices.Contains(schema.Required, name),
+			})
+		}
+	}
+	if !g.jsonSchema2020() {
+		return properties, nil
+	}
+	for _, branch := range []*base.SchemaProxy{schema.Then, schema.Else} {
+		if branch == nil {
+			continue
+		}
+		branchSchema, err := branch.BuildSchema()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid conditional branch %s", branch.GetReference())
+		}
+		branchProperties, err := g.collectProperties(branchSchema)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range branchProperties {
+			p.required = false
+			add(p)
+		}
+	}
+	return properties, nil
+}
+
+type unionVariant struct {
+	field  string
+	proxy  *base.SchemaProxy
+	schema *base.Schema
+}
+
+// unionVariants names a field for each of the union members, after the referenced schema when there is one.
+func unionVariants(members []*base.SchemaProxy) ([]unionVariant, error) {
+	variants := make([]unionVariant, 0, len(members))
+	used := make(map[string]bool)
+	for idx, member := range members {
+		schema, err := member.BuildSchema()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid union member %s", member.GetReference())
+		}
+		field := ""
+		switch {
+		case member.IsReference():
+			field = goIdentifier(refName(member.GetReference()), "Variant")
+		case len(schema.Type) > 0:
+			field = caps.ToCamel(schema.Type[0])
+		}
+		if field == "" || used[field] {
+			field = fmt.Sprintf("%sVariant%d", field, idx)
+		}
+		used[field] = true
+		variants = append(variants, unionVariant{field: field, proxy: member, schema: schema})
+	}
+	return variants, nil
+}
+
+// discriminatorMapping returns the discriminator values of each variant field, either from the explicit mapping of
+// the discriminator or implicitly from the name of the referenced schemas.
+func discriminatorMapping(discriminator *base.Discriminator, variants []unionVariant) map[string][]string {
+	mapping := make(map[string][]string)
+	for _, variant := range variants {
+		if values := discriminatorValues(discriminator, variant.proxy); len(values) > 0 {
+			mapping[variant.field] = values
+		}
+	}
+	return mapping
+}
+
+// discriminatorValues returns the discriminator values selecting a referenced variant, either from the explicit
+// mapping of the discriminator or implicitly from the name of the referenced schema. Inline variants have none.
+func discriminatorValues(discriminator *base.Discriminator, member *base.SchemaProxy) []string {
+	if !member.IsReference() {
+		return nil
+	}
+	ref := member.GetReference()
+	var values []string
+	if discriminator.Mapping != nil {
+		for value := range discriminator.Mapping.KeysFromOldest() {
+			if target := discriminator.Mapping.Value(value); target == ref || refName(target) == refName(ref) {
+				values = append(values, value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		values = append(values, refName(ref))
+	}
+	return values
+}
+
+// generateUnion emits a struct holding one pointer field per variant of a `oneOf` or `anyOf` schema, along with
+// custom JSON marshalling selecting the variant from the discriminator when there is one, or by trial decoding.
+// The object variants set on an `anyOf` union are merged when marshalling, while `oneOf` unions fail to marshal or
+// decode as soon as more than one variant is involved.
+func (g *Generator) generateUnion(f *jen.File, name string, schema *base.Schema, members []*base.SchemaProxy, exclusive bool) error {
+	variants, err := unionVariants(members)
+	if err != nil {
+		return errors.Wrapf(err, "invalid union %s", name)
+	}
+	fields := make([]jen.Code, 0, len(variants))
+	for _, variant := range variants {
+		stmt := jen.Id(variant.field).Op("*")
+		if err := g.oas3TypeToGoType(stmt, variant.proxy, variant.schema, name+variant.field); err != nil {
+			return errors.Wrapf(err, "invalid union %s variant %s", name, variant.field)
+		}
+		fields = append(fields, stmt)
+	}
+	discriminated := schema.Discriminator != nil && schema.Discriminator.PropertyName != ""
+	variantNames := joinWords(slices.Map(variants, func(in unionVariant) string { return in.field }))
+	switch {
+	case !exclusive:
+		f.Commentf("%s holds any of %s, the properties of the variants set being merged when marshalling.", name, variantNames)
+		f.Comment("Every variant the data matches is decoded, their unknown properties being ignored.")
+	case discriminated:
+		f.Commentf("%s holds one of %s, marshalling fails when several of them are set.", name, variantNames)
+		f.Commentf("Its variant is decoded according to its `%s` property.", schema.Discriminator.PropertyName)
+	default:
+		f.Commentf("%s holds one of %s, marshalling fails when several of them are set.", name, variantNames)
+		f.Comment("Its variant is decoded by trial, failing when the data matches several of them.")
+	}
+	f.Type().Id(name).Struct(fields...)
+
+	g.generateUnionHelpers()
+	f.Func().Params(jen.Id("u").Id(name)).Id("MarshalJSON").Params().Parens(jen.List(jen.Index().Byte(), jen.Error())).
+		BlockFunc(func(group *jen.Group) {
+			group.Var().Id("variants").Index().Any()
+			for _, variant := range variants {
+				group.If(jen.Id("u").Dot(variant.field).Op("!=").Nil()).Block(
+					jen.Id("variants").Op("=").Append(jen.Id("variants"), jen.Id("u").Dot(variant.field)),
+				)
+			}
+			group.Return(jen.Id("marshalUnion").Call(jen.Lit(name), jen.Lit(exclusive), jen.Id("variants")))
+		})
+
+	f.Func().Params(jen.Id("u").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().
+		BlockFunc(func(group *jen.Group) {
+			group.Op("*").Id("u").Op("=").Id(name).Values()
+			group.If(jen.String().Parens(jen.Id("data")).Op("==").Lit("null")).Block(jen.Return(jen.Nil()))
+			if discriminated {
+				generateDiscriminatedUnmarshal(group, name, schema.Discriminator, variants)
+				return
+			}
+			generateTrialUnmarshal(group, name, variants, exclusive)
+		})
+	return nil
+}
+
+func generateDiscriminatedUnmarshal(group *jen.Group, name string, discriminator *base.Discriminator, variants []unionVariant) {
+	mapping := discriminatorMapping(discriminator, variants)
+	group.Var().Id("discriminator").Struct(
+		jen.Id("Value").String().Tag(map[string]string{"json": discriminator.PropertyName}),
+	)
+	group.If(
+		jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("discriminator")),
+		jen.Err().Op("!=").Nil(),
+	).Block(jen.Return(jen.Err()))
+	group.Switch(jen.Id("discriminator").Dot("Value")).BlockFunc(func(group *jen.Group) {
+		for _, variant := range variants {
+			values := mapping[variant.field]
+			if len(values) == 0 {
+				continue
+			}
+			group.Case(slices.Map(values, func(in string) jen.Code { return jen.Lit(in) })...).Block(
+				jen.Return(jen.Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("data"), jen.Op("&").Id("u").Dot(variant.field),
+				)),
+			)
+		}
+	})
+	group.Return(jen.Qual("fmt", "Errorf").Call(
+		jen.Lit(fmt.Sprintf("unknown %s %s %%q", name, discriminator.PropertyName)),
+		jen.Id("discriminator").Dot("Value"),
+	))
+}
+
+func generateTrialUnmarshal(group *jen.Group, name string, variants []unionVariant, exclusive bool) {
+	group.Id("matched").Op(":=").Lit(0)
+	for _, variant := range variants {
+		group.BlockFunc(func(group *jen.Group) {
+			group.Id("decoder").Op(":=").Qual("encoding/json", "NewDecoder").Call(
+				jen.Qual("bytes", "NewReader").Call(jen.Id("data")),
+			)
+			// the properties of the variants of an anyOf union are merged, so none of them knows every property
+			if exclusive {
+				group.Id("decoder").Dot("DisallowUnknownFields").Call()
+			}
+			group.If(
+				jen.Err().Op(":=").Id("decoder").Dot("Decode").Call(jen.Op("&").Id("u").Dot(variant.field)),
+				jen.Err().Op("==").Nil(),
+			).Block(
+				jen.Id("matched").Op("++"),
+			).Else().Block(
+				jen.Id("u").Dot(variant.field).Op("=").Nil(),
+			)
+		})
+	}
+	group.If(jen.Id("matched").Op("==").Lit(0)).Block(
+		jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(fmt.Sprintf("data does not match any variant of %s", name)))),
+	)
+	if exclusive {
+		group.If(jen.Id("matched").Op(">").Lit(1)).Block(
+			jen.Op("*").Id("u").Op("=").Id(name).Values(),
+			jen.Return(jen.Qual("fmt", "Errorf").Call(
+				jen.Lit(fmt.Sprintf("data matches %%d variants of %s, which holds one of them", name)), jen.Id("matched"),
+			)),
+		)
+	}
+	group.Return(jen.Nil())
+}
+
+// generateUnionHelpers emits the `marshalUnion` helper shared by the unions of the `dtos` package, once.
+func (g *Generator) generateUnionHelpers() {
+	if _, exists := g.files[path.Join(g.flags.DTOsPackageName, "unions.go")]; exists {
+		return
+	}
+	f := g.generatePackageFile(g.flags.DTOsPackageName, g.flags.DTOsPackageName, "unions")
+	jsonPackage := "encoding/json"
+	f.Comment("marshalUnion marshals the variants set of the named union. The properties of the variants of an anyOf")
+	f.Comment("union are merged, which fails unless they are all objects, while a oneOf union holds a single variant.")
+	f.Func().Id("marshalUnion").
+		Params(jen.Id("name").String(), jen.Id("exclusive").Bool(), jen.Id("variants").Index().Any()).
+		Parens(jen.List(jen.Index().Byte(), jen.Error())).
+		Block(
+			jen.Switch().Block(
+				jen.Case(jen.Len(jen.Id("variants")).Op("==").Lit(0)).Block(
+					jen.Return(jen.Index().Byte().Parens(jen.Lit("null")), jen.Nil()),
+				),
+				jen.Case(jen.Len(jen.Id("variants")).Op("==").Lit(1)).Block(
+					jen.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("variants").Index(jen.Lit(0)))),
+				),
+				jen.Case(jen.Id("exclusive")).Block(
+					jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+						jen.Lit("%d variants of %s are set, which holds one of them"), jen.Len(jen.Id("variants")), jen.Id("name"),
+					)),
+				),
+			),
+			jen.Id("merged").Op(":=").Make(jen.Map(jen.String()).Qual(jsonPackage, "RawMessage")),
+			jen.For(jen.List(jen.Id("_"), jen.Id("variant")).Op(":=").Range().Id("variants")).Block(
+				jen.List(jen.Id("data"), jen.Err()).Op(":=").Qual(jsonPackage, "Marshal").Call(jen.Id("variant")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+				jen.Var().Id("properties").Map(jen.String()).Qual(jsonPackage, "RawMessage"),
+				jen.If(
+					jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("properties")),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+						jen.Lit("cannot merge the variants set of %s, which are not all objects"), jen.Id("name"),
+					)),
+				),
+				jen.Qual("maps", "Copy").Call(jen.Id("merged"), jen.Id("properties")),
+			),
+			jen.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("merged"))),
+		)
+}
+
+// joinWords joins the given words as an english enumeration.
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	}
+	result := ""
+	for idx, word := range words[:len(words)-1] {
+		if idx > 0 {
+			result += ", "
+		}
+		result += word
+	}
+	return result + " or " + words[len(words)-1]
+}
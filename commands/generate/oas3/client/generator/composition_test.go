@@ -0,0 +1,16 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestCompositionGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "composition", spec: "composition"},
+		{name: "allof", spec: "allof"},
+	})
+}
+
+func TestUnions(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "composition", spec: "composition"}, "composition")
+}
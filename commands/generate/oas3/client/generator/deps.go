@@ -0,0 +1,225 @@
+package generator
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// renderedImports lists the imports of the rendered files which do not belong to the standard library, sorted.
+func renderedImports(rendered map[string][]byte) ([]string, error) {
+	unique := make(map[string]bool)
+	fileSet := token.NewFileSet()
+	for filename, content := range rendered {
+		f, err := parser.ParseFile(fileSet, filename, content, parser.ImportsOnly)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the imports of %s", filename)
+		}
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid import %s in %s", spec.Path.Value, filename)
+			}
+			if !isStandardImport(importPath) {
+				unique[importPath] = true
+			}
+		}
+	}
+	imports := make([]string, 0, len(unique))
+	for importPath := range unique {
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+// isStandardImport reports whether the import path belongs to the standard library, whose first path element has no
+// dot.
+func isStandardImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// isWithinModule reports whether the import path belongs to the module.
+func isWithinModule(importPath, modulePath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
+
+// pinnedModule returns the module providing the import path among the dependencies rodent-cli was built with, so that
+// the generated code uses the very same versions.
+func pinnedModule(importPath string) (*debug.Module, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, false
+	}
+	var pinned *debug.Module
+	for _, dep := range info.Deps {
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			dep = &debug.Module{Path: dep.Path, Version: dep.Replace.Version, Sum: dep.Replace.Sum}
+		}
+		if isWithinModule(importPath, dep.Path) && (pinned == nil || len(dep.Path) > len(pinned.Path)) {
+			pinned = dep
+		}
+	}
+	return pinned, pinned != nil
+}
+
+// moduleRequirements returns the requirements of the generated module, pinned to the versions rodent-cli was built
+// with. Imports provided by other modules are left to `go mod tidy`, unless working offline.
+func (g *Generator) moduleRequirements(rendered map[string][]byte) ([]*debug.Module, error) {
+	imports, err := renderedImports(rendered)
+	if err != nil {
+		return nil, err
+	}
+	requirements := make([]*debug.Module, 0)
+	required := make(map[string]bool)
+	for _, importPath := range imports {
+		if isWithinModule(importPath, g.moduleName) {
+			continue
+		}
+		pinned, ok := pinnedModule(importPath)
+		if !ok {
+			if g.flags.Offline {
+				return nil, errors.Newf("cannot resolve the module providing %s offline, rodent-cli was not built with it", importPath)
+			}
+			continue
+		}
+		if !required[pinned.Path] {
+			required[pinned.Path] = true
+			requirements = append(requirements, pinned)
+		}
+	}
+	if g.flags.Offline {
+		// the requirements do not always declare their own requirements, rodent does not require golang.org/x/mod for
+		// instance, the other dependencies of rodent-cli are pinned as well, `go mod tidy` dropping the unused ones
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return requirements, nil
+		}
+		for _, dep := range info.Deps {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				dep = &debug.Module{Path: dep.Path, Version: dep.Replace.Version, Sum: dep.Replace.Sum}
+			}
+			if !required[dep.Path] && !isWithinModule(g.moduleName, dep.Path) {
+				required[dep.Path] = true
+				requirements = append(requirements, dep)
+			}
+		}
+	}
+	return requirements, nil
+}
+
+// toolchainVersionRE matches the language version within the version of a go toolchain, such as go1.24.3 or
+// devel go1.25-abcdef.
+var toolchainVersionRE = regexp.MustCompile(`go(\d+)\.(\d+)`)
+
+// goVersionRE matches the valid versions of the go directive.
+var goVersionRE = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// toolchainGoVersion returns the language version of the local go toolchain, such as 1.24.0 for go1.24.3, falling
+// back to the toolchain rodent-cli was built with when there is none.
+func toolchainGoVersion() string {
+	version := runtime.Version()
+	if output, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
+		version = string(output)
+	}
+	match := toolchainVersionRE.FindStringSubmatch(version)
+	if match == nil {
+		return ""
+	}
+	return match[1] + "." + match[2] + ".0"
+}
+
+// moduleGoVersion returns the version of the go directive of the generated module, either explicit or the one of the
+// local toolchain, which must not be lower than the version required by the generated code.
+func (g *Generator) moduleGoVersion() (string, error) {
+	version := g.flags.GoVersion
+	if version == "" {
+		version = toolchainGoVersion()
+		if version == "" || semver.Compare("v"+version, "v"+g.goVersion) < 0 {
+			version = g.goVersion
+		}
+	}
+	if !goVersionRE.MatchString(version) {
+		return "", errors.Newf("invalid go version %s, expected a version such as 1.24.0", version)
+	}
+	if semver.Compare("v"+version, "v"+g.goVersion) < 0 {
+		return "", errors.Newf("go version %s is lower than %s, which is required by the generated code", version, g.goVersion)
+	}
+	return version, nil
+}
+
+// seedGoSum adds the checksums of the requirements, as recorded in the build info of rodent-cli, to the go.sum file
+// of the generated module, so that the modules fetched by `go mod tidy` are verified against them.
+func seedGoSum(filename string, requirements []*debug.Module) error {
+	current, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read %s", filename)
+	}
+	lines := slices.Filter(strings.Split(string(current), "\n"), func(line string) bool { return line != "" })
+	for _, requirement := range requirements {
+		if requirement.Sum == "" {
+			continue
+		}
+		line := requirement.Path + " " + requirement.Version + " " + requirement.Sum
+		if !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", filename)
+	}
+	return nil
+}
+
+// tidyModule runs `go mod tidy` in the generated module. Offline, modules are only resolved from the local module
+// cache, and the checksum database is not consulted: the checksums of the direct requirements are seeded from the
+// build info, the other ones are computed from the module cache.
+func (g *Generator) tidyModule(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	args := []string{"mod", "tidy"}
+	env := os.Environ()
+	if g.flags.Offline {
+		// test dependencies of the requirements are usually missing from the module cache, and are not needed
+		args = append(args, "-e")
+		env = append(env, "GOPROXY=off", "GOSUMDB=off", "GOFLAGS=-mod=mod")
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = g.outputDir
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "failed to run go mod tidy: %s", output)
+	}
+	if !g.flags.Offline {
+		return nil
+	}
+	log.Debug("go mod tidy", zap.ByteString("output", output))
+	// `go mod tidy -e` succeeds even when the imports of the generated code cannot be resolved
+	cmd = exec.CommandContext(ctx, "go", "list", "-deps", "./...")
+	cmd.Dir = g.outputDir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=readonly")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to resolve the dependencies of the generated module offline: %s", output)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleGoVersion(t *testing.T) {
+	cases := []struct {
+		name      string
+		goVersion string
+		flag      string
+		expected  string
+		err       string
+	}{
+		{name: "explicit", goVersion: "1.23.0", flag: "1.25", expected: "1.25"},
+		{name: "toolchain", goVersion: "1.23.0", expected: toolchainGoVersion()},
+		{name: "required by the generated code", goVersion: "99.0.0", expected: "99.0.0"},
+		{name: "invalid", goVersion: "1.23.0", flag: "go1.25", err: "invalid go version go1.25"},
+		{name: "too low", goVersion: "1.24.0", flag: "1.23.0", err: "go version 1.23.0 is lower than 1.24.0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &Generator{goVersion: tc.goVersion, flags: Flags{GoVersion: tc.flag}}
+			version, err := g.moduleGoVersion()
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestOfflineModule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generating a module runs the go command")
+	}
+	rodent, ok := pinnedModule("github.com/kiwiworks/rodent/web/sdk")
+	require.True(t, ok, "the tests are built with rodent")
+
+	flags := DefaultFlags()
+	flags.OutputDir = t.TempDir()
+	flags.ModuleName = "example.com/offline"
+	flags.GoVersion = "1.23.0"
+	flags.Offline = true
+	// the test binary is not built with all the dependencies of rodent-cli, the errors spec only needs rodent
+	generateSpec(t, "errors", flags)
+
+	goMod, err := os.ReadFile(filepath.Join(flags.OutputDir, "go.mod"))
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`(?m)^go 1\.23\.0$`), string(goMod))
+	require.Contains(t, string(goMod), rodent.Path+" "+rodent.Version, "requirements are pinned to the versions rodent-cli is built with")
+	goSum, err := os.ReadFile(filepath.Join(flags.OutputDir, "go.sum"))
+	require.NoError(t, err)
+	require.Contains(t, string(goSum), rodent.Path+" "+rodent.Version+" "+rodent.Sum)
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// enclosingModule is an existing module the code is generated into, when no module of its own is generated.
+type enclosingModule struct {
+	// dir is the directory of the go.mod file.
+	dir string
+	// Module, Go and Require are decoded from the output of `go mod edit -json`, which understands the go.mod files of
+	// every version of the toolchain.
+	Module struct {
+		Path string
+	}
+	Go      string
+	Require []struct {
+		Path string
+	}
+}
+
+// findEnclosingModule looks for the go.mod file of the module the directory belongs to, going up from the directory
+// itself, which does not need to exist yet. It returns nil when there is none.
+func findEnclosingModule(dir string) (*enclosingModule, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid directory %s", dir)
+	}
+	for {
+		filename := filepath.Join(dir, "go.mod")
+		_, err := os.Stat(filename)
+		if err == nil {
+			return readEnclosingModule(dir)
+		}
+		if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "failed to stat %s", filename)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// readEnclosingModule reads the go.mod file of the module living in dir.
+func readEnclosingModule(dir string) (*enclosingModule, error) {
+	cmd := exec.Command("go", "mod", "edit", "-json")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the go.mod file of %s", dir)
+	}
+	m := &enclosingModule{dir: dir}
+	if err := json.Unmarshal(output, m); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the go.mod file of %s", dir)
+	}
+	if m.Module.Path == "" {
+		return nil, errors.Newf("the go.mod file of %s does not declare a module path", dir)
+	}
+	return m, nil
+}
+
+// importPath returns the import path of the package living in dir, within the module.
+func (m *enclosingModule) importPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid directory %s", dir)
+	}
+	rel, err := filepath.Rel(m.dir, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to locate %s within module %s", dir, m.dir)
+	}
+	return path.Join(m.Module.Path, filepath.ToSlash(rel)), nil
+}
+
+// provides reports whether the import path belongs to the module itself or to one of its requirements.
+func (m *enclosingModule) provides(importPath string) bool {
+	if isWithinModule(importPath, m.Module.Path) {
+		return true
+	}
+	for _, require := range m.Require {
+		if isWithinModule(importPath, require.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingRequirements lists the imports of the rendered files which are neither provided by the module nor by the
+// standard library, pinned to the version of the module providing them when rodent-cli was built with it.
+func (m *enclosingModule) missingRequirements(rendered map[string][]byte) ([]string, error) {
+	imports, err := renderedImports(rendered)
+	if err != nil {
+		return nil, err
+	}
+	missing := make([]string, 0)
+	for _, importPath := range imports {
+		if m.provides(importPath) {
+			continue
+		}
+		if pinned, ok := pinnedModule(importPath); ok {
+			importPath = pinned.Path + "@" + pinned.Version
+		}
+		if !slices.Contains(missing, importPath) {
+			missing = append(missing, importPath)
+		}
+	}
+	return missing, nil
+}
+
+// requireGoVersion raises the go directive of the module to the version required by the generated code, such as the
+// 1.24.0 of the `omitzero` tags of the nullable style, when it is lower.
+func (m *enclosingModule) requireGoVersion(ctx context.Context, version string) error {
+	log := logger.FromContext(ctx)
+	// a module without go directive is assumed to be written for go 1.16
+	if m.Go != "" && semver.Compare("v"+m.Go, "v"+version) >= 0 {
+		return nil
+	}
+	log.Info("raising the go version of the enclosing module",
+		zap.String("module", m.Module.Path), zap.String("from", m.Go), zap.String("to", version))
+	cmd := exec.CommandContext(ctx, "go", "mod", "edit", "-go="+version)
+	cmd.Dir = m.dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "failed to raise the go version of module %s to %s: %s", m.Module.Path, version, output)
+	}
+	m.Go = version
+	return nil
+}
+
+// requireImports adds the modules providing the imports of the rendered files to the module, when it does not
+// require them already.
+func (m *enclosingModule) requireImports(ctx context.Context, rendered map[string][]byte) error {
+	log := logger.FromContext(ctx)
+	imports, err := m.missingRequirements(rendered)
+	if err != nil {
+		return err
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+	log.Info("adding requirements to the enclosing module",
+		zap.String("module", m.Module.Path), zap.Strings("imports", imports))
+	cmd := exec.CommandContext(ctx, "go", append([]string{"get"}, imports...)...)
+	cmd.Dir = m.dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "failed to add requirements to module %s: %s", m.Module.Path, output)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnclosingModule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("reading the enclosing module runs the go command")
+	}
+	cases := []struct {
+		name      string
+		style     string
+		goVersion string
+	}{
+		{name: "pointer style keeps the go version", style: OptionalStylePointer, goVersion: "1.23.0"},
+		{name: "nullable style raises the go version", style: OptionalStyleNullable, goVersion: "1.24.0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// the module requires the dependencies of rodent-cli, so that no requirement is missing
+			writeTestModule(t, dir, "example.com/mono")
+			flags := DefaultFlags()
+			flags.OutputDir = filepath.Join(dir, "api")
+			flags.GenerateModule = false
+			flags.OptionalStyle = tc.style
+			generateSpec(t, "nullable", flags)
+
+			enclosing, err := readEnclosingModule(dir)
+			require.NoError(t, err)
+			require.Equal(t, tc.goVersion, enclosing.Go)
+			_, err = os.Stat(filepath.Join(flags.OutputDir, "go.mod"))
+			require.True(t, os.IsNotExist(err), "no go.mod is generated within the enclosing module")
+			client, err := os.ReadFile(filepath.Join(flags.OutputDir, "client.go"))
+			require.NoError(t, err)
+			require.Contains(t, string(client), `"example.com/mono/api/dtos"`)
+
+			cmd := exec.Command("go", "vet", "./...")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod")
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, "go vet failed:\n%s", output)
+		})
+	}
+}
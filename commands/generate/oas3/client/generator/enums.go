@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// enumVarNamesExtensions lists the extensions that can be used to name the constants of an enum, by order of preference.
+var enumVarNamesExtensions = []string{"x-enum-varnames", "x-enumNames"}
+
+// isEnumSchema reports whether the schema is generated as a named enum type.
+func (g *Generator) isEnumSchema(schema *base.Schema) bool {
+	if len(g.enumNodes(schema)) == 0 {
+		return false
+	}
+	kind := g.schemaType(schema)
+	return kind == "string" || kind == "integer" || kind == "number"
+}
+
+type enumValue struct {
+	name    string
+	literal any
+}
+
+// enumVarNames returns the constant names provided through one of the enumVarNamesExtensions, if any.
+func enumVarNames(schema *base.Schema) []string {
+	if schema.Extensions == nil {
+		return nil
+	}
+	for _, extension := range enumVarNamesExtensions {
+		node := schema.Extensions.Value(extension)
+		if node == nil || node.Kind != yaml.SequenceNode {
+			continue
+		}
+		return slices.Map(node.Content, func(in *yaml.Node) string { return in.Value })
+	}
+	return nil
+}
+
+// enumConstantName builds a valid Go identifier for an enum value, prefixed by the enum type name.
+func enumConstantName(typeName, value string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == ' ' || r == '.' {
+			return r
+		}
+		return ' '
+	}, value)
+	suffix := caps.ToCamel(strings.TrimSpace(cleaned))
+	suffix = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, suffix)
+	if suffix == "" {
+		suffix = "Empty"
+	}
+	return typeName + suffix
+}
+
+func enumValues(typeName string, schema *base.Schema, nodes []*yaml.Node, goKind string) ([]enumValue, error) {
+	varNames := enumVarNames(schema)
+	values := make([]enumValue, 0, len(nodes))
+	used := make(map[string]bool)
+	for idx, node := range nodes {
+		if node.Tag == "!!null" {
+			continue
+		}
+		var literal any
+		switch goKind {
+		case "int64":
+			value, err := strconv.ParseInt(node.Value, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid integer enum value %s", node.Value)
+			}
+			// rendered as an untyped constant
+			literal = int(value)
+		case "float64":
+			value, err := strconv.ParseFloat(node.Value, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid number enum value %s", node.Value)
+			}
+			literal = value
+		default:
+			literal = node.Value
+		}
+		name := enumConstantName(typeName, node.Value)
+		if idx < len(varNames) && varNames[idx] != "" {
+			name = typeName + caps.ToCamel(varNames[idx])
+		}
+		if used[name] {
+			name = fmt.Sprintf("%s%d", name, idx)
+		}
+		used[name] = true
+		values = append(values, enumValue{name: name, literal: literal})
+	}
+	return values, nil
+}
+
+// generateEnum emits a named type for an enum schema, along with a constant per value and the helpers validating them.
+func (g *Generator) generateEnum(f *jen.File, name string, schema *base.Schema) error {
+	goKind := "string"
+	underlying := jen.String()
+	switch g.schemaType(schema) {
+	case "integer":
+		goKind, underlying = "int64", jen.Int64()
+	case "number":
+		goKind, underlying = "float64", jen.Float64()
+	}
+	values, err := enumValues(name, schema, g.enumNodes(schema), goKind)
+	if err != nil {
+		return errors.Wrapf(err, "invalid enum %s", name)
+	}
+	g.generateLenientEnums()
+
+	if schema.Description != "" {
+		f.Comment(strings.TrimSpace(schema.Description))
+	} else {
+		f.Commentf("%s enumerates the values allowed by the %s schema.", name, name)
+	}
+	f.Type().Id(name).Add(underlying)
+	f.Const().DefsFunc(func(group *jen.Group) {
+		for _, value := range values {
+			group.Id(value.name).Id(name).Op("=").Lit(value.literal)
+		}
+	})
+
+	f.Commentf("Values returns all the values known to %s.", name)
+	f.Func().Params(jen.Id(name)).Id("Values").Params().Index().Id(name).Block(
+		jen.Return(jen.Index().Id(name).ValuesFunc(func(group *jen.Group) {
+			for _, value := range values {
+				group.Id(value.name)
+			}
+		})),
+	)
+
+	f.Commentf("IsValid reports whether the value is one of the values known to %s.", name)
+	f.Func().Params(jen.Id("e").Id(name)).Id("IsValid").Params().Bool().Block(
+		jen.Switch(jen.Id("e")).BlockFunc(func(group *jen.Group) {
+			if len(values) > 0 {
+				group.Case(slices.Map(values, func(in enumValue) jen.Code { return jen.Id(in.name) })...).Block(
+					jen.Return(jen.True()),
+				)
+			}
+		}),
+		jen.Return(jen.False()),
+	)
+
+	f.Commentf("UnmarshalJSON rejects the values unknown to %s, unless SetLenientEnums enabled them.", name)
+	f.Func().Params(jen.Id("e").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().
+		Block(
+			jen.Var().Id("value").Add(underlying.Clone()),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("value")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.If(jen.Op("!").Id(name).Call(jen.Id("value")).Dot("IsValid").Call().Op("&&").Op("!").Id("lenientEnums").Dot("Load").Call()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(fmt.Sprintf("invalid %s value %%v", name)), jen.Id("value"))),
+			),
+			jen.Op("*").Id("e").Op("=").Id(name).Call(jen.Id("value")),
+			jen.Return(jen.Nil()),
+		)
+	return nil
+}
+
+// generateLenientEnums emits the switch controlling the validation of enum values, once. encoding/json gives no way
+// to configure a single decoder, so the switch is process wide and atomic, keeping it free of data races.
+func (g *Generator) generateLenientEnums() {
+	if _, exists := g.files[path.Join(g.flags.DTOsPackageName, "enums.go")]; exists {
+		return
+	}
+	f := g.generatePackageFile(g.flags.DTOsPackageName, g.flags.DTOsPackageName, "enums")
+	f.Comment("lenientEnums holds the setting of SetLenientEnums.")
+	f.Var().Id("lenientEnums").Qual("sync/atomic", "Bool")
+	f.Comment("SetLenientEnums disables the validation of enum values when decoding, so that values introduced by newer")
+	f.Comment("versions of the API are kept as is instead of failing. The setting applies to the whole process, it is meant")
+	f.Comment("to be set once at init rather than toggled by tests running in parallel.")
+	f.Func().Id("SetLenientEnums").Params(jen.Id("lenient").Bool()).Block(
+		jen.Id("lenientEnums").Dot("Store").Call(jen.Id("lenient")),
+	)
+}
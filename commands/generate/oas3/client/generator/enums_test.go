@@ -0,0 +1,15 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestEnumsGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "enums", spec: "enums"},
+	})
+}
+
+func TestEnumValidation(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "enums", spec: "enums"}, "enums")
+}
@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// errorContentTypes lists the content types error bodies are decoded from, by order of preference.
+var errorContentTypes = []string{"application/json", "application/problem+json"}
+
+type errorResponse struct {
+	code     string
+	typeName string
+	schema   *base.SchemaProxy
+}
+
+// isRange reports whether the error response covers a range of status codes, such as `4XX`.
+func (e errorResponse) isRange() bool {
+	return strings.HasSuffix(strings.ToUpper(e.code), "XX")
+}
+
+// precedence ranks the error response among the cases of the decoding switch: exact status codes come first, then
+// the ranges which would otherwise shadow them, then the default response.
+func (e errorResponse) precedence() int {
+	switch {
+	case e.code == "default":
+		return 2
+	case e.isRange():
+		return 1
+	default:
+		return 0
+	}
+}
+
+// condition returns the expression matching the status code(s) of the error response.
+func (e errorResponse) condition(statusCode *jen.Statement) jen.Code {
+	if e.code == "default" {
+		return nil
+	}
+	if e.isRange() {
+		lower, _ := strconv.Atoi(e.code[:1] + "00")
+		return statusCode.Clone().Op(">=").Lit(lower).Op("&&").Add(statusCode.Clone()).Op("<").Lit(lower + 100)
+	}
+	code, _ := strconv.Atoi(e.code)
+	return statusCode.Clone().Op("==").Lit(code)
+}
+
+// errorSchema returns the schema of the first JSON content of the response.
+func errorSchema(response *v3.Response) *base.SchemaProxy {
+	if response.Content == nil {
+		return nil
+	}
+	for _, contentType := range errorContentTypes {
+		if mediaType := response.Content.Value(contentType); mediaType != nil && mediaType.Schema != nil {
+			return mediaType.Schema
+		}
+	}
+	for contentType := range response.Content.KeysFromOldest() {
+		if mediaType := response.Content.Value(contentType); strings.HasSuffix(contentType, "+json") && mediaType.Schema != nil {
+			return mediaType.Schema
+		}
+	}
+	return nil
+}
+
+// errorTypeName names the typed error of the given status code, using the http status text when known.
+func errorTypeName(methodName, code string) string {
+	if code == "default" {
+		return methodName + "DefaultError"
+	}
+	if statusCode, err := strconv.Atoi(code); err == nil && http.StatusText(statusCode) != "" {
+		return methodName + caps.ToCamel(http.StatusText(statusCode)) + "Error"
+	}
+	return methodName + strings.ToUpper(code) + "Error"
+}
+
+// operationErrorResponses lists the documented error responses of the operation having a JSON body, exact status
+// codes first, then ranges and the default response, each in the given ordering.
+func operationErrorResponses(methodName string, operation *v3.Operation, ordering string) []errorResponse {
+	if operation.Responses == nil {
+		return nil
+	}
+	responses := make([]errorResponse, 0)
+	hasSuccess := false
+	if operation.Responses.Codes != nil {
+		for _, code := range orderedKeys(ordering, operation.Responses.Codes) {
+			if strings.HasPrefix(code, "2") {
+				hasSuccess = true
+			}
+			if !strings.HasPrefix(code, "4") && !strings.HasPrefix(code, "5") {
+				continue
+			}
+			if schema := errorSchema(operation.Responses.Codes.Value(code)); schema != nil {
+				responses = append(responses, errorResponse{
+					code:     code,
+					typeName: errorTypeName(methodName, code),
+					schema:   schema,
+				})
+			}
+		}
+	}
+	// the default response is the successful one when no 2XX response is documented
+	if hasSuccess && operation.Responses.Default != nil {
+		if schema := errorSchema(operation.Responses.Default); schema != nil {
+			responses = append(responses, errorResponse{
+				code:     "default",
+				typeName: errorTypeName(methodName, "default"),
+				schema:   schema,
+			})
+		}
+	}
+	sort.SliceStable(responses, func(i, j int) bool { return responses[i].precedence() < responses[j].precedence() })
+	return responses
+}
+
+// generateResponseError emits the `ResponseError` type and the response interceptor capturing it.
+func (g *Generator) generateResponseError() {
+	f := g.generatePackageFile("", g.flags.PackageName, "errors")
+
+	f.Comment("ResponseError is returned when the API replies with an unsuccessful status code that is not documented")
+	f.Comment("by the operation, typed errors are decoded from it otherwise.")
+	f.Type().Id("ResponseError").Struct(
+		jen.Id("StatusCode").Int(),
+		jen.Id("Header").Qual("net/http", "Header"),
+		jen.Id("Body").Index().Byte(),
+	)
+	f.Func().Params(jen.Id("e").Op("*").Id("ResponseError")).Id("Error").Params().String().Block(
+		jen.Return(jen.Qual("fmt", "Sprintf").Call(
+			jen.Lit("server replied with '%d' status: %s"),
+			jen.Id("e").Dot("StatusCode"),
+			jen.String().Parens(jen.Id("e").Dot("Body")),
+		)),
+	)
+
+	f.Comment("captureResponseError keeps unsuccessful responses around so they can be decoded into typed errors.")
+	f.Func().Id("captureResponseError").
+		Params(jen.Id("_").Qual("context", "Context"), jen.Id("resp").Op("*").Qual("net/http", "Response")).
+		Error().
+		BlockFunc(func(group *jen.Group) {
+			group.If(jen.Id("resp").Dot("StatusCode").Op(">=").Lit(200).Op("&&").Id("resp").Dot("StatusCode").Op("<").Lit(400)).
+				Block(jen.Return(jen.Nil()))
+			group.List(jen.Id("body"), jen.Err()).Op(":=").Qual("io", "ReadAll").Call(jen.Id("resp").Dot("Body"))
+			group.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit("failed to read error response"))),
+			)
+			group.Return(jen.Op("&").Id("ResponseError").Values(jen.Dict{
+				jen.Id("StatusCode"): jen.Id("resp").Dot("StatusCode"),
+				jen.Id("Header"):     jen.Id("resp").Dot("Header"),
+				jen.Id("Body"):       jen.Id("body"),
+			}))
+		})
+}
+
+// generateOperationErrors emits a typed error per documented error response of the operation, and the function
+// decoding them from a `ResponseError`. It returns the name of that function, or an empty string if the operation
+// documents no error response.
+func (g *Generator) generateOperationErrors(f *jen.File, methodName, method, apiPath string, operation *v3.Operation) (string, error) {
+	responses := operationErrorResponses(methodName, operation, g.flags.Ordering)
+	if len(responses) == 0 {
+		return "", nil
+	}
+	for _, response := range responses {
+		bodyType := jen.Id("Body")
+		if err := g.schemaProxyToGoType(bodyType, response.schema, response.typeName+"Body"); err != nil {
+			return "", errors.Wrapf(err, "invalid %s error response type", response.code)
+		}
+		if response.code == "default" {
+			f.Commentf("%s is returned by %s when the API replies with an undocumented error status code.", response.typeName, methodName)
+		} else {
+			f.Commentf("%s is returned by %s when the API replies with a %s status code.", response.typeName, methodName, response.code)
+		}
+		f.Type().Id(response.typeName).Struct(
+			jen.Id("StatusCode").Int(),
+			jen.Id("Header").Qual("net/http", "Header"),
+			bodyType,
+		)
+		f.Func().Params(jen.Id("e").Op("*").Id(response.typeName)).Id("Error").Params().String().Block(
+			jen.Return(jen.Qual("fmt", "Sprintf").Call(
+				jen.Lit(fmt.Sprintf("%s %s: server replied with '%%d' status", method, apiPath)),
+				jen.Id("e").Dot("StatusCode"),
+			)),
+		)
+	}
+
+	decoder := "decode" + methodName + "Error"
+	f.Commentf("%s converts a ResponseError into the typed error documented for its status code.", decoder)
+	f.Func().Id(decoder).Params(jen.Err().Error()).Error().BlockFunc(func(group *jen.Group) {
+		group.Id("responseErr").Op(":=").Qual(errPackage, "As").Index(jen.Op("*").Id("ResponseError")).Call(jen.Err())
+		group.If(jen.Id("responseErr").Op("==").Nil()).Block(jen.Return(jen.Err()))
+		group.Id("raw").Op(":=").Op("*").Id("responseErr")
+		statusCode := jen.Id("raw").Dot("StatusCode")
+		group.Var().Id("typed").Error()
+		group.Var().Id("body").Any()
+		group.Switch().BlockFunc(func(group *jen.Group) {
+			for _, response := range responses {
+				caseBody := []jen.Code{
+					jen.Id("typedErr").Op(":=").Op("&").Id(response.typeName).Values(jen.Dict{
+						jen.Id("StatusCode"): statusCode.Clone(),
+						jen.Id("Header"):     jen.Id("raw").Dot("Header"),
+					}),
+					jen.List(jen.Id("typed"), jen.Id("body")).Op("=").List(jen.Id("typedErr"), jen.Op("&").Id("typedErr").Dot("Body")),
+				}
+				if condition := response.condition(statusCode); condition != nil {
+					group.Case(condition).Block(caseBody...)
+				} else {
+					group.Default().Block(caseBody...)
+				}
+			}
+		})
+		group.If(jen.Id("typed").Op("==").Nil()).Block(jen.Return(jen.Err()))
+		group.If(
+			jen.Id("decodingErr").Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("raw").Dot("Body"), jen.Id("body")),
+			jen.Id("decodingErr").Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit("failed to decode error response: %s"), jen.Id("decodingErr"))),
+		)
+		group.Return(jen.Id("typed"))
+	})
+	return decoder, nil
+}
@@ -0,0 +1,19 @@
+package generator
+
+import (
+	"testing"
+)
+
+// errorsCase keeps the order of the spec, which documents the 4XX range before the 404 status code it covers.
+var errorsCase = goldenCase{name: "errors", spec: "errors", flags: func(flags *Flags) { flags.Ordering = OrderingSpec }}
+
+func TestErrorsGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "errors_sorted", spec: "errors"},
+		errorsCase,
+	})
+}
+
+func TestTypedErrors(t *testing.T) {
+	runBehaviour(t, errorsCase, "errors")
+}
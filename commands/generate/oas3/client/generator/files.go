@@ -0,0 +1,270 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// generatedHeader is the comment starting every generated file, followed by `DO NOT EDIT.` as defined by the go
+// command.
+const generatedHeader = "Code generated by github.com/kiwiworks/rodent-cli."
+
+// ErrOutOfDate is reported in check mode when the generated code differs from the one on disk.
+var ErrOutOfDate = errors.Newf("generated code is out of date")
+
+type changeKind string
+
+const (
+	fileCreated  changeKind = "created"
+	fileModified changeKind = "modified"
+	fileDeleted  changeKind = "deleted"
+)
+
+// fileChange describes how a file of the output directory changes once the code is generated.
+type fileChange struct {
+	filename string
+	kind     changeKind
+	current  []byte
+	rendered []byte
+}
+
+// renderFiles renders the generated files in memory, keyed by their path relative to the output directory.
+func (g *Generator) renderFiles() (map[string][]byte, error) {
+	rendered := make(map[string][]byte, len(g.files))
+	for file, f := range g.files {
+		var buf bytes.Buffer
+		if err := f.Render(&buf); err != nil {
+			return nil, errors.Wrapf(err, "failed to render %s", file)
+		}
+		rendered[file] = buf.Bytes()
+	}
+	return rendered, nil
+}
+
+// planChanges compares the rendered files with the ones on disk, making sure the existing ones can be overwritten,
+// and lists the stale files to delete. The go.mod and go.sum files are left out, since they are rewritten by
+// `go mod tidy`.
+func (g *Generator) planChanges(ctx context.Context, rendered map[string][]byte) ([]fileChange, error) {
+	changes := make([]fileChange, 0)
+	for file, content := range rendered {
+		filename := path.Join(g.outputDir, file)
+		current, err := os.ReadFile(filename)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, fileChange{filename: filename, kind: fileCreated, rendered: content})
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to read %s", filename)
+		case !bytes.Equal(current, content):
+			if err := g.checkOverwrite(file, current); err != nil {
+				return nil, err
+			}
+			changes = append(changes, fileChange{filename: filename, kind: fileModified, current: current, rendered: content})
+		}
+	}
+	if g.flags.GenerateModule {
+		for _, file := range moduleFiles {
+			filename := path.Join(g.outputDir, file)
+			current, err := os.ReadFile(filename)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read %s", filename)
+			}
+			if err := g.checkOverwrite(file, current); err != nil {
+				return nil, err
+			}
+		}
+	}
+	stale, err := g.staleFiles(ctx, rendered)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, stale...)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].filename < changes[j].filename })
+	return changes, nil
+}
+
+// staleFiles lists the files owned by the generated package which are not generated anymore, such as the ones of
+// operations removed from the spec. Without a manifest, the files generated by rodent-cli living next to the rendered
+// ones are considered owned.
+func (g *Generator) staleFiles(ctx context.Context, rendered map[string][]byte) ([]fileChange, error) {
+	log := logger.FromContext(ctx)
+	owned := make([]string, 0)
+	if g.manifest != nil {
+		owned = g.manifest.packageFiles(g.flags.PackageName)
+	} else {
+		dirs := make(map[string]bool)
+		for file := range rendered {
+			dirs[path.Dir(file)] = true
+		}
+		for dir := range dirs {
+			entries, err := os.ReadDir(path.Join(g.outputDir, dir))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to list directory %s", path.Join(g.outputDir, dir))
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+					owned = append(owned, path.Join(dir, entry.Name()))
+				}
+			}
+		}
+	}
+	stale := make([]fileChange, 0)
+	for _, file := range owned {
+		if _, exists := rendered[file]; exists || g.flags.GenerateModule && slices.Contains(moduleFiles, file) {
+			continue
+		}
+		filename := path.Join(g.outputDir, file)
+		current, err := os.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to read %s", filename)
+		}
+		if g.manifest == nil && !isRodentCode(current) {
+			continue
+		}
+		if strings.HasSuffix(file, ".go") && !isGeneratedCode(current) {
+			log.Warn("keeping stale file which is not marked as generated anymore", zap.String("filename", filename))
+			continue
+		}
+		stale = append(stale, fileChange{filename: filename, kind: fileDeleted, current: current})
+	}
+	return stale, nil
+}
+
+// applyChanges writes the created and modified files, creating their directories as needed, and removes the deleted
+// ones.
+func applyChanges(ctx context.Context, changes []fileChange) error {
+	log := logger.FromContext(ctx)
+	for _, change := range changes {
+		if change.kind == fileDeleted {
+			log.Info("deleting", zap.String("filename", change.filename))
+			if err := os.Remove(change.filename); err != nil {
+				return errors.Wrapf(err, "failed to delete %s", change.filename)
+			}
+			continue
+		}
+		log.Info("saving", zap.String("filename", change.filename))
+		dir := path.Dir(change.filename)
+		stats, err := os.Stat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return errors.Wrapf(err, "failed to create directory %s", dir)
+				}
+			} else {
+				return errors.Wrapf(err, "failed to stat directory %s", dir)
+			}
+		} else if !stats.IsDir() {
+			return errors.Newf("path %s is not a directory", dir)
+		}
+		if err := os.WriteFile(change.filename, change.rendered, 0644); err != nil {
+			return errors.Wrapf(err, "failed to save %s", change.filename)
+		}
+	}
+	return nil
+}
+
+// diff returns the unified diff of the change, created and deleted files being diffed against /dev/null.
+func (c fileChange) diff() (string, error) {
+	fromFile, toFile := c.filename, c.filename
+	switch c.kind {
+	case fileCreated:
+		fromFile = os.DevNull
+	case fileDeleted:
+		toFile = os.DevNull
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(c.current)),
+		B:        difflib.SplitLines(string(c.rendered)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to diff %s", c.filename)
+	}
+	return diff, nil
+}
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// colorizeDiff highlights the lines of a unified diff with ANSI colors.
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for idx, line := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+		if content := strings.TrimSuffix(line, "\n"); color != "" {
+			lines[idx] = color + content + colorReset + line[len(content):]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// isTerminal reports whether the file is a terminal, in which case diffs written to it are colorized.
+func isTerminal(f *os.File) bool {
+	stats, err := f.Stat()
+	return err == nil && stats.Mode()&os.ModeCharDevice != 0
+}
+
+// printDiffs writes the unified diff of every change to w.
+func printDiffs(w io.Writer, changes []fileChange, color bool) error {
+	for _, change := range changes {
+		diff, err := change.diff()
+		if err != nil {
+			return err
+		}
+		if color {
+			diff = colorizeDiff(diff)
+		}
+		if _, err := fmt.Fprint(w, diff); err != nil {
+			return errors.Wrapf(err, "failed to write the diff of %s", change.filename)
+		}
+	}
+	return nil
+}
+
+// printChanges lists the changes to w, one file per line.
+func printChanges(w io.Writer, changes []fileChange) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintf(w, "%-8s %s\n", change.kind, change.filename); err != nil {
+			return errors.Wrapf(err, "failed to list changes")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// captureStdout returns what fn writes to os.Stdout, where the check and dry-run modes report the changes. Tests
+// using it must not run in parallel.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- content
+	}()
+	fn()
+	require.NoError(t, writer.Close())
+	return string(<-output)
+}
+
+func TestCheck(t *testing.T) {
+	tc := goldenCase{name: "petstore", spec: "petstore"}
+	flags := tc.generate(t)
+	generated := readTree(t, flags.OutputDir, "")
+	flags.Check = true
+
+	output := captureStdout(t, func() { generateSpec(t, tc.spec, flags) })
+	require.Empty(t, output, "up to date code has no diff")
+
+	client := filepath.Join(flags.OutputDir, "client.go")
+	require.NoError(t, os.WriteFile(client, []byte(generated["client.go"]+"// edited\n"), 0644))
+	var err error
+	output = captureStdout(t, func() { err = buildSpec(t, tc.spec, flags) })
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrOutOfDate), "got %v", err)
+	require.Contains(t, output, "client.go")
+	require.Contains(t, output, "-// edited")
+
+	edited, err := os.ReadFile(client)
+	require.NoError(t, err)
+	require.Equal(t, generated["client.go"]+"// edited\n", string(edited), "check mode does not write")
+}
+
+func TestDryRun(t *testing.T) {
+	tc := goldenCase{name: "petstore", spec: "petstore"}
+	flags := tc.generate(t)
+	generated := readTree(t, flags.OutputDir, "")
+
+	client := filepath.Join(flags.OutputDir, "client.go")
+	require.NoError(t, os.WriteFile(client, []byte(generated["client.go"]+"// edited\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(flags.OutputDir, "errors.go")))
+	flags.DryRun = true
+	output := captureStdout(t, func() { generateSpec(t, tc.spec, flags) })
+	require.Equal(t, "modified "+client+"\ncreated  "+filepath.Join(flags.OutputDir, "errors.go")+"\n", output)
+
+	flags.Diff = true
+	output = captureStdout(t, func() { generateSpec(t, tc.spec, flags) })
+	require.Contains(t, output, "modified "+client+"\n")
+	require.Contains(t, output, "-// edited")
+	after := readTree(t, flags.OutputDir, "")
+	require.NotContains(t, after, "errors.go", "dry-run mode does not write")
+	require.Equal(t, generated["client.go"]+"// edited\n", after["client.go"], "dry-run mode does not write")
+
+	// the operations grouped by tag live in files of their own, which are not generated anymore without grouping
+	grouped := flags
+	grouped.DryRun, grouped.Diff = false, false
+	grouped.Grouping = GroupingTag
+	generateSpec(t, tc.spec, grouped)
+	generated = readTree(t, flags.OutputDir, "")
+	flags.Diff = false
+	output = captureStdout(t, func() { generateSpec(t, tc.spec, flags) })
+	require.Contains(t, output, "deleted  "+filepath.Join(flags.OutputDir, "pets_client.go")+"\n")
+
+	require.Equal(t, generated, readTree(t, flags.OutputDir, ""), "dry-run mode does not write")
+}
@@ -3,36 +3,81 @@ package generator
 import (
 	"context"
 	"os"
-	"os/exec"
-	"path"
+	"path/filepath"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 
 	"github.com/kiwiworks/rodent/errors"
 	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
 )
 
 type Generator struct {
 	model      *libopenapi.DocumentModel[v3.Document]
 	outputDir  string
 	moduleName string
-	files      map[string]*jen.File
-	imports    []Import
+	// goVersion is the minimum go version required by the generated code.
+	goVersion string
+	flags     Flags
+	files     map[string]*jen.File
+	imports   []Import
+	// typeNames holds the names used by the `dtos` package, along with the schema they were generated from.
+	typeNames map[string]*base.SchemaProxy
+	// inlineTypes holds the names generated for inline schemas.
+	inlineTypes map[inlineSchemaKey]string
+	// schemaLocations locates the nodes of the component schemas, so that the inline schemas they declare are
+	// generated once, however they are reached.
+	schemaLocations map[*yaml.Node]inlineSchemaKey
+	// componentTypes holds the names of the types generated for the component schemas, by schema name.
+	componentTypes map[string]string
+	// refTypes holds the names generated for referenced schemas living outside of the `components` section.
+	refTypes map[string]string
+	// manifest lists the files previously generated into the output directory, if any.
+	manifest *manifest
+	// methodNames holds the names of the methods generated for the selected operations.
+	methodNames map[*v3.Operation]string
+	// formSchemas holds the root nodes of the schemas encoded as url encoded forms.
+	formSchemas map[*yaml.Node]bool
+	// hasSecurity is set when the client authenticates its requests through the security schemes of the document.
+	hasSecurity bool
 }
 
-func GeneratorFromFile(path string) (*Generator, error) {
+// GeneratorFromFile creates a generator from the spec at path, resolving its relative references against its
+// directory. Remote references are only followed for the allowed hosts, and fetched within ctx.
+func GeneratorFromFile(ctx context.Context, path string, allowedHosts []string) (*Generator, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid spec path %s", path)
+	}
 	specBytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read %s", path)
 	}
-	document, err := libopenapi.NewDocument(specBytes)
+	config := documentConfiguration(ctx, path, allowedHosts)
+	document, err := libopenapi.NewDocumentWithConfiguration(specBytes, config)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse %s", path)
 	}
+	if isSwaggerVersion(document.GetVersion()) {
+		converted, warnings, err := convertSwagger(specBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert %s to OpenAPI 3", path)
+		}
+		log := logger.New()
+		for _, warning := range warnings {
+			log.Warn("lossy swagger 2.0 conversion", zap.String("filename", path), zap.String("detail", warning))
+		}
+		if document, err = libopenapi.NewDocumentWithConfiguration(converted, config); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s once converted to OpenAPI 3", path)
+		}
+	}
 	model, errs := document.BuildV3Model()
 	if errs != nil {
 		return nil, errors.Wrapf(multierr.Combine(errs...), "failed to build model from %s", path)
@@ -42,65 +87,179 @@ func GeneratorFromFile(path string) (*Generator, error) {
 
 func NewGenerator(model *libopenapi.DocumentModel[v3.Document]) *Generator {
 	return &Generator{
-		model:   model,
-		files:   make(map[string]*jen.File),
-		imports: []Import{},
+		model:           model,
+		goVersion:       "1.23.0",
+		files:           make(map[string]*jen.File),
+		imports:         []Import{},
+		typeNames:       make(map[string]*base.SchemaProxy),
+		inlineTypes:     make(map[inlineSchemaKey]string),
+		schemaLocations: make(map[*yaml.Node]inlineSchemaKey),
+		formSchemas:     make(map[*yaml.Node]bool),
+		refTypes:        make(map[string]string),
+		componentTypes:  make(map[string]string),
 	}
 }
 
 func (g *Generator) Build(ctx context.Context, flags Flags) error {
+	optionalStyle, err := validateOptionalStyle(flags.OptionalStyle)
+	if err != nil {
+		return err
+	}
+	flags.OptionalStyle = optionalStyle
+	if flags.Ordering, err = validateOrdering(flags.Ordering); err != nil {
+		return err
+	}
+	if flags.Grouping, err = validateGrouping(flags.Grouping); err != nil {
+		return err
+	}
+	switch flags.Target {
+	case "":
+		flags.Target = TargetClient
+	case TargetClient, TargetServer, TargetMock:
+	default:
+		return errors.Newf("invalid target %s, expected %s, %s or %s", flags.Target, TargetClient, TargetServer, TargetMock)
+	}
+	if flags.Target == TargetServer && flags.OptionalStyle == OptionalStyleNullable {
+		// huma describes and validates the request bodies from their Go types, which it cannot do for the custom
+		// marshalling of Optional[T] and Nullable[T]
+		return errors.Newf("the %s optional style is not supported by the server target", OptionalStyleNullable)
+	}
+	if flags.PackageName == "" {
+		flags.PackageName = flags.Target
+	}
+	if flags.DTOsPackageName == "" {
+		flags.DTOsPackageName = "dtos"
+	}
+	for _, packageName := range slices.Of(flags.PackageName, flags.DTOsPackageName) {
+		if err := validatePackageName(packageName); err != nil {
+			return err
+		}
+	}
+	if flags.PackageName == flags.DTOsPackageName {
+		return errors.Newf("the %s and dtos packages must have different names, both are named %s", flags.Target, flags.PackageName)
+	}
+	if err := validateOperationFilter(flags.Include); err != nil {
+		return errors.Wrapf(err, "invalid include filter")
+	}
+	if err := validateOperationFilter(flags.Exclude); err != nil {
+		return errors.Wrapf(err, "invalid exclude filter")
+	}
+	if err := validateTypeMappings(flags.TypeMappings, flags.FormatMappings); err != nil {
+		return err
+	}
+	g.flags = flags
 	outputDir := flags.OutputDir
 	g.outputDir = outputDir
-	g.moduleName = g.model.Model.Info.Title
 	log := logger.FromContext(ctx)
+	var enclosing *enclosingModule
+	if !flags.GenerateModule {
+		if enclosing, err = findEnclosingModule(outputDir); err != nil {
+			return err
+		}
+	}
+	g.moduleName = flags.ModuleName
+	if g.moduleName == "" && enclosing != nil {
+		if g.moduleName, err = enclosing.importPath(outputDir); err != nil {
+			return err
+		}
+	}
+	if g.moduleName == "" {
+		if !flags.GenerateModule {
+			log.Warn("no go.mod found above the output directory, the generated packages are imported using the title of the spec",
+				zap.String("output", outputDir))
+		}
+		g.moduleName = sanitizeModulePath(g.model.Model.Info.Title)
+	}
+	if err := validateModulePath(g.moduleName); err != nil {
+		return err
+	}
 
+	// documents whose schemas all live in other files may have no components section
+	componentSchemas := orderedmap.New[string, *base.SchemaProxy]()
+	if g.model.Model.Components != nil && g.model.Model.Components.Schemas != nil {
+		componentSchemas = g.model.Model.Components.Schemas
+	}
 	log.Info("spec metadata",
 		zap.String("title", g.model.Model.Info.Title),
 		zap.String("version", g.model.Model.Info.Version),
+		zap.String("openapi", g.model.Model.Version),
 		zap.Int("paths", g.model.Model.Paths.PathItems.Len()),
-		zap.Int("components.schemas", g.model.Model.Components.Schemas.Len()),
+		zap.Int("components.schemas", componentSchemas.Len()),
 	)
 
-	if err := g.generateSchemas(g.model.Model.Components.Schemas); err != nil {
+	if err := g.collectFormBodies(g.model.Model); err != nil {
+		return err
+	}
+	if err := g.generateSchemas(componentSchemas); err != nil {
+		return err
+	}
+	if err := g.assignMethodNames(g.model.Model); err != nil {
 		return err
 	}
 
-	if err := g.generateClientPackage(g.model.Model); err != nil {
+	switch flags.Target {
+	case TargetServer:
+		err = g.generateServerPackage(g.model.Model)
+	case TargetMock:
+		err = g.generateMockPackage(g.model.Model)
+	default:
+		err = g.generateClientPackage(g.model.Model)
+	}
+	if err != nil {
 		return err
 	}
 
-	for filename, f := range g.files {
-		filename = path.Join(outputDir, filename)
-		log.Info("saving", zap.String("filename", filename))
-		dir := path.Dir(filename)
-		stats, err := os.Stat(dir)
-		if err != nil {
-			if os.IsNotExist(err) {
-				if err := os.MkdirAll(dir, 0755); err != nil {
-					return errors.Wrapf(err, "failed to create directory %s", dir)
-				}
-			} else {
-				return errors.Wrapf(err, "failed to stat directory %s", dir)
-			}
-		} else if !stats.IsDir() {
-			return errors.Newf("path %s is not a directory", dir)
+	rendered, err := g.renderFiles()
+	if err != nil {
+		return err
+	}
+	if g.manifest, err = readManifest(outputDir); err != nil {
+		return err
+	}
+	changes, err := g.planChanges(ctx, rendered)
+	if err != nil {
+		return err
+	}
+	switch {
+	case flags.Check:
+		if err := printDiffs(os.Stdout, changes, isTerminal(os.Stdout)); err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			return errors.Wrapf(ErrOutOfDate, "%d file(s) in %s would change", len(changes), outputDir)
+		}
+		return nil
+	case flags.DryRun:
+		if err := printChanges(os.Stdout, changes); err != nil {
+			return err
 		}
-		if err := f.Save(filename); err != nil {
-			return errors.Wrapf(err, "failed to save %s", filename)
+		if flags.Diff {
+			return printDiffs(os.Stdout, changes, isTerminal(os.Stdout))
 		}
+		return nil
+	}
+	if err := applyChanges(ctx, changes); err != nil {
+		return err
+	}
+	owned := make([]string, 0, len(rendered)+len(moduleFiles))
+	for file := range rendered {
+		owned = append(owned, file)
 	}
-
 	if flags.GenerateModule {
-		if err := g.generateModule(); err != nil {
+		owned = append(owned, moduleFiles...)
+	}
+	if err := g.writeManifest(owned); err != nil {
+		return err
+	}
+	if enclosing != nil {
+		if err := enclosing.requireGoVersion(ctx, g.goVersion); err != nil {
 			return err
 		}
+		return enclosing.requireImports(ctx, rendered)
+	}
 
-		cmd := exec.Command("go", "mod", "tidy")
-		cmd.Dir = outputDir
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return errors.Wrapf(err, "failed to run go mod tidy: %s", output)
-		}
+	if flags.GenerateModule {
+		return g.generateModule(ctx, rendered)
 	}
 	return nil
 }
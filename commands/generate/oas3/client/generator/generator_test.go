@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+
+	"github.com/kiwiworks/rodent/logger"
+)
+
+// update rewrites the golden files from the generated code, run `go test ./... -run Golden -update` after an
+// intentional change of the generated code.
+var update = flag.Bool("update", false, "rewrite the golden files with the generated code")
+
+// goldenSuffix is appended to the generated files stored in testdata/golden, so that they are not mistaken for
+// sources of the repository.
+const goldenSuffix = ".golden"
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	logger.SetLevel(logger.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+// goldenCase generates a spec of testdata/specs, named without its extension, with the default flags altered by
+// flags, if set.
+type goldenCase struct {
+	name  string
+	spec  string
+	flags func(flags *Flags)
+}
+
+// generate generates the code of the case into a temporary directory, as the example.com/<name> module, and returns
+// the flags it was generated with.
+func (tc goldenCase) generate(t *testing.T) Flags {
+	t.Helper()
+	flags := DefaultFlags()
+	flags.OutputDir = t.TempDir()
+	flags.GenerateModule = false
+	flags.ModuleName = "example.com/" + tc.name
+	if tc.flags != nil {
+		tc.flags(&flags)
+	}
+	generateSpec(t, tc.spec, flags)
+	return flags
+}
+
+// generateSpec generates the code of a spec of testdata/specs, named without its extension, with the given flags.
+func generateSpec(t *testing.T, spec string, flags Flags) {
+	t.Helper()
+	require.NoError(t, buildSpec(t, spec, flags))
+}
+
+// buildSpec builds a spec of testdata/specs like generateSpec, returning the error of the build.
+func buildSpec(t *testing.T, spec string, flags Flags) error {
+	t.Helper()
+	ctx := context.Background()
+	g, err := GeneratorFromFile(ctx, filepath.Join("testdata", "specs", spec+".yaml"), nil)
+	require.NoError(t, err)
+	return g.Build(ctx, flags)
+}
+
+// runGoldenCases compares the code generated for each case with the files of testdata/golden/<name>, then vets it.
+func runGoldenCases(t *testing.T, cases []goldenCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			flags := tc.generate(t)
+			generated := readTree(t, flags.OutputDir, "")
+			delete(generated, manifestFilename)
+
+			goldenDir := filepath.Join("testdata", "golden", tc.name)
+			if *update {
+				require.NoError(t, os.RemoveAll(goldenDir))
+				for filename, content := range generated {
+					golden := filepath.Join(goldenDir, filepath.FromSlash(filename)+goldenSuffix)
+					require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0755))
+					require.NoError(t, os.WriteFile(golden, []byte(content), 0644))
+				}
+			}
+			golden := readTree(t, goldenDir, goldenSuffix)
+			require.Equal(t, sortedFilenames(golden), sortedFilenames(generated), "generated files")
+			for _, filename := range sortedFilenames(generated) {
+				require.Equal(t, golden[filename], generated[filename], "content of %s", filename)
+			}
+			runGo(t, flags.OutputDir, flags.ModuleName, "vet", "./...")
+		})
+	}
+}
+
+// runCompileCases vets the code generated for each case, for the combinations of flags not worth a golden file.
+func runCompileCases(t *testing.T, cases []goldenCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			flags := tc.generate(t)
+			runGo(t, flags.OutputDir, flags.ModuleName, "vet", "./...")
+		})
+	}
+}
+
+// runBehaviour runs the tests of testdata/behaviour/<behaviour> against the code generated for the case, from the
+// behaviour package of the generated module.
+func runBehaviour(t *testing.T, tc goldenCase, behaviour string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("running the generated code runs the go command")
+	}
+	flags := tc.generate(t)
+	sources, err := filepath.Glob(filepath.Join("testdata", "behaviour", behaviour, "*.go"))
+	require.NoError(t, err)
+	require.NotEmpty(t, sources, "no behaviour tests for %s", behaviour)
+	dir := filepath.Join(flags.OutputDir, "behaviour")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(source)), content, 0644))
+	}
+	runGo(t, flags.OutputDir, flags.ModuleName, "test", "./behaviour")
+}
+
+// runGo turns dir into the moduleName module, then runs the go command with the given arguments from it, failing
+// the test with its output when it fails. It is skipped in -short mode.
+func runGo(t *testing.T, dir, moduleName string, args ...string) {
+	t.Helper()
+	if testing.Short() {
+		return
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go command is not available")
+	}
+	writeTestModule(t, dir, moduleName)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "go %s failed:\n%s", strings.Join(args, " "), output)
+}
+
+// writeTestModule turns dir into a module requiring the dependencies of rodent-cli, at the versions of its go.mod
+// file, so that the generated code builds from the local module cache.
+func writeTestModule(t *testing.T, dir, moduleName string) {
+	t.Helper()
+	output, err := exec.Command("go", "env", "GOMOD").Output()
+	require.NoError(t, err)
+	goModPath := strings.TrimSpace(string(output))
+	content, err := os.ReadFile(goModPath)
+	require.NoError(t, err)
+	parent := modfile.ModulePath(content)
+	require.NotEmpty(t, parent)
+	goMod := strings.Replace(string(content), "module "+parent, "module "+moduleName, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+	goSum, err := os.ReadFile(filepath.Join(filepath.Dir(goModPath), "go.sum"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+}
+
+// readTree reads the files of dir, keyed by their slash separated path relative to it, the given suffix trimmed.
+func readTree(t *testing.T, dir, suffix string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimSuffix(filepath.ToSlash(relative), suffix)] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func sortedFilenames(files map[string]string) []string {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+func TestOrderingGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "petstore", spec: "petstore"},
+		{name: "petstore_spec_order", spec: "petstore", flags: func(flags *Flags) { flags.Ordering = OrderingSpec }},
+	})
+}
+
+func TestOrderingIsStable(t *testing.T) {
+	tc := goldenCase{name: "petstore", spec: "petstore"}
+	first := readTree(t, tc.generate(t).OutputDir, "")
+	for range 5 {
+		require.Equal(t, first, readTree(t, tc.generate(t).OutputDir, ""))
+	}
+}
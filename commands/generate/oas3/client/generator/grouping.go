@@ -0,0 +1,225 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+const (
+	// GroupingNone generates every operation as a method of the Client.
+	GroupingNone = "none"
+	// GroupingTag groups the operations into sub-clients by their first tag.
+	GroupingTag = "tag"
+	// GroupingPath groups the operations into sub-clients by the first segment of their path, once the segments
+	// shared by every path are stripped.
+	GroupingPath = "path"
+	// GroupingExtension groups the operations into sub-clients by their `x-group` extension, falling back to the one
+	// of their path item.
+	GroupingExtension = "x-group"
+)
+
+// groupExtension is the extension naming the group of an operation or path item with GroupingExtension.
+const groupExtension = "x-group"
+
+// validateGrouping checks the grouping flag, defaulting it to GroupingNone.
+func validateGrouping(grouping string) (string, error) {
+	switch grouping {
+	case "":
+		return GroupingNone, nil
+	case GroupingNone, GroupingTag, GroupingPath, GroupingExtension:
+		return grouping, nil
+	default:
+		return "", errors.Newf(
+			"unsupported grouping %s, expected one of %s, %s, %s or %s",
+			grouping, GroupingNone, GroupingTag, GroupingPath, GroupingExtension,
+		)
+	}
+}
+
+// clientGroup is a set of operations generated as the methods of a sub-client, the root group being the Client
+// itself.
+type clientGroup struct {
+	// field is the name of the Client field holding the sub-client, it is empty for the root group.
+	field    string
+	typeName string
+	file     *jen.File
+	methods  []clientMethod
+}
+
+// accessorName is the name of the method returning the sub-client as its interface, which the interfaces of the root
+// client and its mock expose.
+func (c *clientGroup) accessorName() string {
+	return c.typeName
+}
+
+// interfaceName is the name of the interface implemented by the sub-client and its mock.
+func (c *clientGroup) interfaceName() string {
+	return c.typeName + "Interface"
+}
+
+// mockName is the name of the mock implementing the interface of the sub-client.
+func (c *clientGroup) mockName() string {
+	return "Mock" + c.typeName
+}
+
+// groupName returns the name of the group of the operation according to the grouping flag, or an empty string if it
+// belongs to the root group.
+func (g *Generator) groupName(pathPrefix []string, apiPath string, pathItem *v3.PathItem, operation *v3.Operation) string {
+	switch g.flags.Grouping {
+	case GroupingTag:
+		if len(operation.Tags) > 0 {
+			return operation.Tags[0]
+		}
+	case GroupingPath:
+		for _, segment := range pathSegments(apiPath)[len(pathPrefix):] {
+			if !isPathParam(segment) {
+				return segment
+			}
+		}
+	case GroupingExtension:
+		if name := extensionString(operation.Extensions, groupExtension); name != "" {
+			return name
+		}
+		return extensionString(pathItem.Extensions, groupExtension)
+	}
+	return ""
+}
+
+// extensionString returns the value of a string extension, or an empty string if it is missing.
+func extensionString(extensions *orderedmap.Map[string, *yaml.Node], name string) string {
+	if extensions == nil {
+		return ""
+	}
+	node := extensions.Value(name)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return strings.TrimSpace(node.Value)
+}
+
+// pathSegments splits the path into its non empty segments.
+func pathSegments(apiPath string) []string {
+	return slices.Filter(strings.Split(apiPath, "/"), func(segment string) bool { return segment != "" })
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// commonPathPrefix returns the leading static segments shared by every path, such as `/api/v1`, stopping before the
+// last static segment of any path so that every path keeps one to be grouped by.
+func commonPathPrefix(apiPaths []string) []string {
+	if len(apiPaths) == 0 {
+		return nil
+	}
+	var prefix []string
+	for idx := 0; ; idx++ {
+		var candidate string
+		for pathIdx, apiPath := range apiPaths {
+			segments := pathSegments(apiPath)
+			remaining := slices.Filter(segments[min(idx+1, len(segments)):], func(segment string) bool { return !isPathParam(segment) })
+			if idx >= len(segments) || isPathParam(segments[idx]) || len(remaining) == 0 {
+				return prefix
+			}
+			if pathIdx == 0 {
+				candidate = segments[idx]
+			} else if segments[idx] != candidate {
+				return prefix
+			}
+		}
+		prefix = append(prefix, candidate)
+	}
+}
+
+// groupFieldName turns the name of a group into the name of the Client field holding its sub-client.
+func groupFieldName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, name)
+	field := caps.ToCamel(strings.TrimSpace(cleaned))
+	if field == "" {
+		return ""
+	}
+	if unicode.IsDigit(rune(field[0])) {
+		field = "Group" + field
+	}
+	return field
+}
+
+// clientGroups assigns the selected operations of the document to their group, keyed by operation. The root group
+// comes first, followed by the sub-clients.
+func (g *Generator) clientGroups(document v3.Document, rootFile *jen.File) ([]*clientGroup, map[*v3.Operation]*clientGroup) {
+	root := &clientGroup{typeName: "Client", file: rootFile}
+	groups := []*clientGroup{root}
+	byOperation := make(map[*v3.Operation]*clientGroup)
+
+	apiPaths := orderedKeys(g.flags.Ordering, document.Paths.PathItems)
+	pathPrefix := commonPathPrefix(apiPaths)
+	names := make(map[*v3.Operation]string)
+	var (
+		selected    []*v3.Operation
+		rootMethods []string
+	)
+	for _, apiPath := range apiPaths {
+		pathItem := document.Paths.PathItems.Value(apiPath)
+		for _, op := range pathOperations(pathItem) {
+			if !g.isOperationSelected(apiPath, op.operation) {
+				continue
+			}
+			selected = append(selected, op.operation)
+			names[op.operation] = groupFieldName(g.groupName(pathPrefix, apiPath, pathItem, op.operation))
+			if names[op.operation] == "" {
+				rootMethods = append(rootMethods, g.methodName(op.operation))
+			}
+		}
+	}
+
+	// collides reports whether the field, or its accessor, collides with a reserved name or with a method of the root
+	// client and its `<Method>Func` mock field.
+	collides := func(field string) bool {
+		if slices.Contains(reservedNames, field) {
+			return true
+		}
+		for _, method := range rootMethods {
+			if method == field || method+"Func" == field || method == field+"Client" {
+				return true
+			}
+		}
+		return false
+	}
+	byField := map[string]*clientGroup{"": root}
+	for _, operation := range selected {
+		field := names[operation]
+		if field != "" && collides(field) {
+			field += "API"
+		}
+		group, exists := byField[field]
+		if !exists {
+			group = &clientGroup{
+				field:    field,
+				typeName: field + "Client",
+				file:     g.generatePackageFile("", g.flags.PackageName, caps.ToSnake(field)+"_client"),
+			}
+			byField[field] = group
+			groups = append(groups, group)
+		}
+		byOperation[operation] = group
+	}
+	if g.flags.Ordering == OrderingSorted {
+		sort.SliceStable(groups[1:], func(i, j int) bool { return groups[1+i].field < groups[1+j].field })
+	}
+	return groups, byOperation
+}
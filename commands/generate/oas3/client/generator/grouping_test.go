@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+var groupingTagCase = goldenCase{name: "grouping_tag", spec: "grouping", flags: func(flags *Flags) { flags.Grouping = GroupingTag }}
+
+func TestGroupingGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		groupingTagCase,
+		{name: "grouping_path", spec: "grouping", flags: func(flags *Flags) { flags.Grouping = GroupingPath }},
+		{name: "grouping_extension", spec: "grouping", flags: func(flags *Flags) { flags.Grouping = GroupingExtension }},
+	})
+}
+
+func TestGroupingsCompile(t *testing.T) {
+	cases := make([]goldenCase, 0)
+	for _, grouping := range []string{GroupingNone, GroupingTag, GroupingPath, GroupingExtension} {
+		for _, style := range []string{OptionalStylePointer, OptionalStyleNullable} {
+			cases = append(cases, goldenCase{
+				name: "grouping_" + strings.ReplaceAll(grouping, "-", "_") + "_" + style,
+				spec: "grouping",
+				flags: func(flags *Flags) {
+					flags.Grouping = grouping
+					flags.OptionalStyle = style
+				},
+			})
+		}
+	}
+	runCompileCases(t, cases)
+}
+
+func TestSubClients(t *testing.T) {
+	runBehaviour(t, groupingTagCase, "grouping")
+}
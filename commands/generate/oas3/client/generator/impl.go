@@ -5,22 +5,84 @@ import (
 	"net/url"
 	"os"
 
-	"github.com/cavaliergopher/grab/v3"
 	"go.uber.org/zap"
 
 	"github.com/kiwiworks/rodent/errors"
 	"github.com/kiwiworks/rodent/logger"
 )
 
+const (
+	// TargetClient generates a client of the API.
+	TargetClient = "client"
+	// TargetServer generates the interfaces of the services implementing the API, and the handlers mounting them on
+	// the rodent web server.
+	TargetServer = "server"
+	// TargetMock generates a fake implementation of the API, serving canned responses to tests.
+	TargetMock = "mock"
+)
+
 type Flags struct {
-	OutputDir      string
+	// Target is either TargetClient, TargetServer or TargetMock.
+	Target    string
+	OutputDir string
+	// GenerateModule generates a go.mod file into OutputDir, otherwise the code is generated into the module enclosing
+	// it, if any, which gets the missing requirements of the generated code.
 	GenerateModule bool
+	// OptionalStyle is either OptionalStylePointer or OptionalStyleNullable.
+	OptionalStyle string
+	// AllowedHosts lists the hosts remote references can be fetched from, on top of the one the spec was downloaded
+	// from.
+	AllowedHosts []string
+	// ModuleName is the path of the generated module, which defaults to the sanitized title of the spec.
+	ModuleName string
+	// PackageName is the name of the generated package, it defaults to the name of the target. The client package is
+	// generated at the root of the output directory, the server and mock ones in the directory of the same name.
+	PackageName string
+	// DTOsPackageName is the name of the package holding the types of the schemas, generated in the directory of the
+	// same name.
+	DTOsPackageName string
+	// TypeMappings maps component schemas, by name, to existing Go types written as `import/path.Type`, which are
+	// used instead of generating them.
+	TypeMappings map[string]string
+	// FormatMappings maps `string` and `number` formats to existing Go types written as `import/path.Type`.
+	FormatMappings map[string]string
+	// TypeNames renames the types generated for component schemas.
+	TypeNames map[string]string
+	// MethodNames renames the methods generated for operations, by operation id, taking precedence over their
+	// `x-go-name` extension.
+	MethodNames map[string]string
+	// Include restricts the generated operations to the ones it matches, unless it is empty.
+	Include OperationFilter
+	// Exclude skips the operations it matches.
+	Exclude OperationFilter
+	// Ordering is either OrderingSorted or OrderingSpec.
+	Ordering string
+	// Grouping is either GroupingNone, GroupingTag, GroupingPath or GroupingExtension, it splits the operations of
+	// the client into sub-clients generated in their own file.
+	Grouping string
+	// GoVersion is the version of the go directive of the generated go.mod file, which defaults to the version of the
+	// local toolchain.
+	GoVersion string
+	// Offline generates a complete go.mod and go.sum from the local module cache, without any network access.
+	Offline bool
+	// Check compares the generated code with the one in OutputDir instead of writing it, failing when it is out of
+	// date.
+	Check bool
+	// DryRun lists the files that would be created, modified or deleted instead of writing them.
+	DryRun bool
+	// Diff prints the diff of every file listed by DryRun.
+	Diff bool
 }
 
 func DefaultFlags() Flags {
 	return Flags{
-		OutputDir:      ".",
-		GenerateModule: true,
+		Target:          TargetClient,
+		OutputDir:       ".",
+		GenerateModule:  true,
+		OptionalStyle:   OptionalStylePointer,
+		DTOsPackageName: "dtos",
+		Ordering:        OrderingSorted,
+		Grouping:        GroupingNone,
 	}
 }
 
@@ -33,32 +95,23 @@ func Generate(ctx context.Context, uri url.URL, flags Flags) error {
 	}
 	log.Debug("temporary directory created", zap.String("dir", tmpDir))
 
-	var filename string
+	var (
+		filename     string
+		allowedHosts = flags.AllowedHosts
+	)
 	switch uri.Scheme {
 	case "http", "https":
-		tmpFile, err := os.CreateTemp(tmpDir, "rodent-*")
-		defer func(tmpFile *os.File) {
-			err := tmpFile.Close()
-			if err != nil {
-				log.DPanic("failed to close temporary file", zap.Error(err))
-			}
-		}(tmpFile)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create temp file")
-		}
-		log.Debug("temporary file created", zap.String("filename", tmpFile.Name()))
-		response, err := grab.Get(tmpFile.Name(), uri.String())
+		filename, err = downloadSpec(ctx, tmpDir, uri)
 		if err != nil {
 			return errors.Wrapf(err, "failed to download spec from %s", uri.String())
 		}
-		log.Debug("file downloaded", zap.Int64("size", response.Size()))
-		filename = tmpFile.Name()
+		allowedHosts = append(allowedHosts[:len(allowedHosts):len(allowedHosts)], uri.Hostname())
 	case "file":
 		filename = uri.Path
 	default:
 		return errors.Newf("unsupported scheme %s", uri.Scheme)
 	}
-	generator, err := GeneratorFromFile(filename)
+	generator, err := GeneratorFromFile(ctx, filename, allowedHosts)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create generator")
 	}
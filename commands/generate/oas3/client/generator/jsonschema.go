@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// jsonSchema2020 reports whether the schemas of the document follow JSON Schema 2020-12, as they do starting with
+// OpenAPI 3.1, instead of the OpenAPI 3.0 dialect.
+func (g *Generator) jsonSchema2020() bool {
+	return strings.HasPrefix(g.model.Model.Version, "3.1")
+}
+
+// schemaTypes returns the non-null types of the schema.
+func schemaTypes(schema *base.Schema) []string {
+	return slices.Filter(schema.Type, func(kind string) bool { return kind != "null" })
+}
+
+// schemaType returns the single non-null type of the schema, or an empty string when it has none or several of them.
+// With JSON Schema 2020-12, an untyped schema takes the type of its `const` value, or is an array when it declares
+// `prefixItems`.
+func (g *Generator) schemaType(schema *base.Schema) string {
+	types := schemaTypes(schema)
+	switch {
+	case len(types) == 1:
+		return types[0]
+	case len(types) > 1 || !g.jsonSchema2020():
+		return ""
+	case schema.Const != nil:
+		return nodeType(schema.Const)
+	case len(schema.PrefixItems) > 0:
+		return "array"
+	}
+	return ""
+}
+
+// nodeType returns the JSON Schema type of a scalar value.
+func nodeType(node *yaml.Node) string {
+	switch node.Tag {
+	case "!!str":
+		return "string"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	}
+	return ""
+}
+
+// isNullableSchema reports whether null is an allowed value, through `nullable` with OpenAPI 3.0, or through a `null`
+// type or `const` with JSON Schema 2020-12.
+func (g *Generator) isNullableSchema(schema *base.Schema) bool {
+	if !g.jsonSchema2020() {
+		return schema.Nullable != nil && *schema.Nullable
+	}
+	return slices.Contains(schema.Type, "null") || schema.Const != nil && schema.Const.Tag == "!!null"
+}
+
+// enumNodes returns the values allowed by the schema, a JSON Schema 2020-12 `const` being an enum of a single value.
+func (g *Generator) enumNodes(schema *base.Schema) []*yaml.Node {
+	if len(schema.Enum) == 0 && schema.Const != nil && g.jsonSchema2020() {
+		return []*yaml.Node{schema.Const}
+	}
+	return schema.Enum
+}
+
+// isConditionalObject reports whether the schema is a JSON Schema 2020-12 conditional whose `then` or `else` branch
+// declares properties, which is generated as a struct even without any `allOf` or property of its own.
+func (g *Generator) isConditionalObject(schema *base.Schema) bool {
+	if !g.jsonSchema2020() {
+		return false
+	}
+	for _, branch := range []*base.SchemaProxy{schema.Then, schema.Else} {
+		if branch == nil {
+			continue
+		}
+		branchSchema, err := branch.BuildSchema()
+		if err != nil {
+			continue
+		}
+		if branchSchema.Properties != nil && branchSchema.Properties.Len() > 0 || len(branchSchema.AllOf) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isTupleSchema reports whether the schema is a JSON Schema 2020-12 tuple, declaring the type of its leading items
+// through `prefixItems`.
+func (g *Generator) isTupleSchema(schema *base.Schema) bool {
+	return g.jsonSchema2020() && len(schema.PrefixItems) > 0
+}
+
+// generateTuple emits a struct holding one field per item of a tuple schema, marshalled as a JSON array. Items after
+// the leading ones are kept in a `Rest` field when the schema allows them.
+func (g *Generator) generateTuple(f *jen.File, name string, schema *base.Schema) error {
+	fields := make([]jen.Code, 0, len(schema.PrefixItems)+1)
+	for idx, item := range schema.PrefixItems {
+		field := fmt.Sprintf("Item%d", idx)
+		stmt := jen.Id(field)
+		if err := g.schemaProxyToGoType(stmt, item, name+field); err != nil {
+			return errors.Wrapf(err, "invalid tuple %s item %d", name, idx)
+		}
+		fields = append(fields, stmt)
+	}
+	restType := jen.Null()
+	hasRest := schema.Items != nil && schema.Items.IsA() && schema.Items.A != nil
+	if hasRest {
+		if err := g.schemaProxyToGoType(restType, schema.Items.A, name+"Rest"); err != nil {
+			return errors.Wrapf(err, "invalid tuple %s items", name)
+		}
+		fields = append(fields, jen.Id("Rest").Index().Add(restType))
+	}
+	jsonPackage := "encoding/json"
+
+	if schema.Description != "" {
+		f.Comment(strings.TrimSpace(schema.Description))
+	} else {
+		f.Commentf("%s is a tuple of %d items, marshalled as a JSON array.", name, len(schema.PrefixItems))
+	}
+	f.Type().Id(name).Struct(fields...)
+
+	f.Func().Params(jen.Id("t").Id(name)).Id("MarshalJSON").Params().Parens(jen.List(jen.Index().Byte(), jen.Error())).
+		BlockFunc(func(group *jen.Group) {
+			group.Id("items").Op(":=").Index().Any().ValuesFunc(func(group *jen.Group) {
+				for idx := range schema.PrefixItems {
+					group.Id("t").Dot(fmt.Sprintf("Item%d", idx))
+				}
+			})
+			if hasRest {
+				group.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id("t").Dot("Rest")).Block(
+					jen.Id("items").Op("=").Append(jen.Id("items"), jen.Id("item")),
+				)
+			}
+			group.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("items")))
+		})
+
+	f.Func().Params(jen.Id("t").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().
+		BlockFunc(func(group *jen.Group) {
+			group.Var().Id("items").Index().Qual(jsonPackage, "RawMessage")
+			group.If(
+				jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("items")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err()))
+			group.Op("*").Id("t").Op("=").Id(name).Values()
+			for idx := range schema.PrefixItems {
+				group.If(jen.Len(jen.Id("items")).Op("<=").Lit(idx)).Block(jen.Return(jen.Nil()))
+				group.If(
+					jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(
+						jen.Id("items").Index(jen.Lit(idx)), jen.Op("&").Id("t").Dot(fmt.Sprintf("Item%d", idx)),
+					),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err()))
+			}
+			if hasRest {
+				group.For(jen.List(jen.Id("_"), jen.Id("raw")).Op(":=").Range().Id("items").Index(jen.Lit(len(schema.PrefixItems)), jen.Empty())).Block(
+					jen.Var().Id("item").Add(restType.Clone()),
+					jen.If(
+						jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("raw"), jen.Op("&").Id("item")),
+						jen.Err().Op("!=").Nil(),
+					).Block(jen.Return(jen.Err())),
+					jen.Id("t").Dot("Rest").Op("=").Append(jen.Id("t").Dot("Rest"), jen.Id("item")),
+				)
+			}
+			group.Return(jen.Nil())
+		})
+	return nil
+}
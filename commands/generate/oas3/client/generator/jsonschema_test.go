@@ -0,0 +1,12 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestJSONSchemaGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "jsonschema", spec: "jsonschema"},
+		{name: "conditionals", spec: "conditionals"},
+	})
+}
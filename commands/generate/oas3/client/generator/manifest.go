@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// manifestFilename is the name of the file, within the output directory, listing the files the generator owns.
+const manifestFilename = ".rodent-manifest.yaml"
+
+// moduleFiles are the files generated along with the code when generating a module.
+var moduleFiles = []string{"go.mod", "go.sum"}
+
+// generatedCodeRE matches the comment marking generated Go files, as defined by the go command.
+var generatedCodeRE = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// manifest lists the files the generator owns in the output directory, so that it only ever rewrites or deletes files
+// it generated itself. The files are listed by generated package, a client and a server sharing their `dtos` package
+// can be generated into the same directory.
+type manifest struct {
+	Generator string `yaml:"generator"`
+	// Packages maps the name of the generated packages to their files, relative to the output directory, and sorted.
+	Packages map[string][]string `yaml:"packages"`
+}
+
+// readManifest reads the manifest of the output directory, it returns nil when there is none, which is the case when
+// nothing was generated yet, or when the code was generated by a version of rodent-cli predating manifests.
+func readManifest(outputDir string) (*manifest, error) {
+	filename := path.Join(outputDir, manifestFilename)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+	var m manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return &m, nil
+}
+
+// writeManifest records the files of the generated package in the manifest of the output directory, keeping the ones
+// of the other packages.
+func (g *Generator) writeManifest(files []string) error {
+	m := manifest{Generator: "github.com/kiwiworks/rodent-cli", Packages: make(map[string][]string)}
+	if g.manifest != nil {
+		for packageName, owned := range g.manifest.Packages {
+			m.Packages[packageName] = owned
+		}
+	}
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	m.Packages[g.flags.PackageName] = files
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal manifest")
+	}
+	content = append([]byte("# "+generatedHeader+" DO NOT EDIT.\n"), content...)
+	filename := path.Join(g.outputDir, manifestFilename)
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", filename)
+	}
+	g.manifest = &m
+	return nil
+}
+
+// owns reports whether the manifest lists the file, relative to the output directory, for any package.
+func (m *manifest) owns(file string) bool {
+	if m == nil {
+		return false
+	}
+	for _, files := range m.Packages {
+		if slices.Contains(files, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageFiles returns the files owned by the package which are not shared with any other package.
+func (m *manifest) packageFiles(packageName string) []string {
+	return slices.Filter(m.Packages[packageName], func(file string) bool {
+		for other, files := range m.Packages {
+			if other != packageName && slices.Contains(files, file) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// isGeneratedCode reports whether the Go file is marked as generated, either with the comment defined by the go
+// command, or with the two lines header of earlier versions of rodent-cli.
+func isGeneratedCode(content []byte) bool {
+	return generatedCodeRE.Match(content) || bytes.HasPrefix(content, []byte("// "+generatedHeader+"\n// DO NOT EDIT."))
+}
+
+// isRodentCode reports whether the Go file was generated by rodent-cli.
+func isRodentCode(content []byte) bool {
+	return bytes.HasPrefix(content, []byte("// "+generatedHeader))
+}
+
+// checkOverwrite makes sure an existing file of the output directory can be overwritten: Go files must be marked as
+// generated, and were generated by rodent-cli unless listed in the manifest, module files must be listed in the
+// manifest, or declare the generated module when there is no manifest yet.
+func (g *Generator) checkOverwrite(file string, content []byte) error {
+	filename := path.Join(g.outputDir, file)
+	if slices.Contains(moduleFiles, file) {
+		if g.manifest.owns(file) {
+			return nil
+		}
+		goMod := content
+		if file != "go.mod" {
+			var err error
+			if goMod, err = os.ReadFile(path.Join(g.outputDir, "go.mod")); err != nil {
+				goMod = nil
+			}
+		}
+		if g.manifest == nil && goMod != nil && modfile.ModulePath(goMod) == g.moduleName {
+			return nil
+		}
+		return errors.Newf("refusing to overwrite %s, which was not generated by rodent-cli", filename)
+	}
+	if !isGeneratedCode(content) {
+		return errors.Newf("refusing to overwrite %s, which lacks the `Code generated ... DO NOT EDIT.` header", filename)
+	}
+	if !g.manifest.owns(file) && !isRodentCode(content) {
+		return errors.Newf("refusing to overwrite %s, which was not generated by rodent-cli", filename)
+	}
+	return nil
+}
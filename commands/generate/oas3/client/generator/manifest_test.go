@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestRefusesForeignFiles(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		content string
+		module  bool
+		err     string
+	}{
+		{
+			name:    "hand-written file",
+			file:    "client.go",
+			content: "package client\n\n// Client is hand-written.\ntype Client struct{}\n",
+			err:     "lacks the `Code generated ... DO NOT EDIT.` header",
+		},
+		{
+			name:    "file generated by another tool",
+			file:    "dtos/dtos.go",
+			content: "// Code generated by another-tool. DO NOT EDIT.\n\npackage dtos\n",
+			err:     "which was not generated by rodent-cli",
+		},
+		{
+			name:    "go.mod of another module",
+			file:    "go.mod",
+			content: "module example.com/other\n\ngo 1.23.0\n",
+			module:  true,
+			err:     "which was not generated by rodent-cli",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			flags := DefaultFlags()
+			flags.OutputDir = t.TempDir()
+			flags.GenerateModule = tc.module
+			flags.ModuleName = "example.com/petstore"
+			filename := filepath.Join(flags.OutputDir, filepath.FromSlash(tc.file))
+			require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+			require.NoError(t, os.WriteFile(filename, []byte(tc.content), 0644))
+
+			err := buildSpec(t, "petstore", flags)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+			require.Equal(t, map[string]string{tc.file: tc.content}, readTree(t, flags.OutputDir, ""), "nothing is written")
+		})
+	}
+}
+
+func TestManifestUpdatesOwnedFiles(t *testing.T) {
+	tc := goldenCase{name: "petstore", spec: "petstore", flags: func(flags *Flags) { flags.Grouping = GroupingTag }}
+	flags := tc.generate(t)
+	manifest, err := readManifest(flags.OutputDir)
+	require.NoError(t, err)
+	require.Contains(t, manifest.Packages[TargetClient], "pets_client.go")
+
+	// owned files are rewritten even when edited, and the stale ones deleted, but hand-written files are left alone
+	client := filepath.Join(flags.OutputDir, "client.go")
+	content, err := os.ReadFile(client)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(client, append(content, "// edited\n"...), 0644))
+	helpers := filepath.Join(flags.OutputDir, "helpers.go")
+	require.NoError(t, os.WriteFile(helpers, []byte("package client\n"), 0644))
+	flags.Grouping = GroupingNone
+	generateSpec(t, tc.spec, flags)
+
+	files := readTree(t, flags.OutputDir, "")
+	require.NotContains(t, files["client.go"], "// edited")
+	require.NotContains(t, files, "pets_client.go")
+	require.Equal(t, "package client\n", files["helpers.go"])
+	manifest, err = readManifest(flags.OutputDir)
+	require.NoError(t, err)
+	require.NotContains(t, manifest.Packages[TargetClient], "pets_client.go")
+	require.NotContains(t, manifest.Packages[TargetClient], "helpers.go")
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// additionalProperties returns whether the schema allows additional properties, and their schema when it is typed.
+// With JSON Schema 2020-12, `unevaluatedProperties` is used when `additionalProperties` is not set.
+func (g *Generator) additionalProperties(schema *base.Schema) (*base.SchemaProxy, bool) {
+	additional := schema.AdditionalProperties
+	if additional == nil && g.jsonSchema2020() {
+		additional = schema.UnevaluatedProperties
+	}
+	if additional == nil {
+		return nil, false
+	}
+	if additional.IsA() {
+		return additional.A, additional.A != nil
+	}
+	return nil, additional.B
+}
+
+// isDictionarySchema reports whether the schema only declares additional properties, and is generated as a map.
+func (g *Generator) isDictionarySchema(schema *base.Schema) bool {
+	if _, allowed := g.additionalProperties(schema); !allowed {
+		return false
+	}
+	hasProperties := schema.Properties != nil && schema.Properties.Len() > 0
+	return !hasProperties && len(schema.AllOf) == 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0
+}
+
+// additionalPropertiesType writes the Go type of the additional properties of the schema into stmt.
+func (g *Generator) additionalPropertiesType(stmt *jen.Statement, schema *base.Schema, name string) error {
+	valueProxy, _ := g.additionalProperties(schema)
+	if valueProxy == nil {
+		stmt.Any()
+		return nil
+	}
+	if err := g.schemaProxyToGoType(stmt, valueProxy, name+"Value"); err != nil {
+		return errors.Wrapf(err, "invalid additional properties type")
+	}
+	return nil
+}
+
+// generateAdditionalPropertiesMarshalling emits the JSON marshalling of a struct having both fixed and additional
+// properties, so that unknown keys round-trip through its `AdditionalProperties` field.
+func (g *Generator) generateAdditionalPropertiesMarshalling(f *jen.File, name string, schema *base.Schema, properties []property) error {
+	valueType := jen.Null()
+	if err := g.additionalPropertiesType(valueType, schema, name); err != nil {
+		return errors.Wrapf(err, "invalid schema %s", name)
+	}
+	jsonPackage := "encoding/json"
+
+	f.Commentf("MarshalJSON merges the additional properties of %s with its fixed ones.", name)
+	f.Func().Params(jen.Id("o").Id(name)).Id("MarshalJSON").Params().Parens(jen.List(jen.Index().Byte(), jen.Error())).
+		Block(
+			jen.Type().Id("known").Id(name),
+			jen.List(jen.Id("data"), jen.Err()).Op(":=").Qual(jsonPackage, "Marshal").Call(jen.Id("known").Call(jen.Id("o"))),
+			jen.If(jen.Err().Op("!=").Nil().Op("||").Len(jen.Id("o").Dot("AdditionalProperties")).Op("==").Lit(0)).Block(
+				jen.Return(jen.Id("data"), jen.Err()),
+			),
+			jen.Id("fields").Op(":=").Make(jen.Map(jen.String()).Qual(jsonPackage, "RawMessage")),
+			jen.If(
+				jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("fields")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.For(jen.List(jen.Id("key"), jen.Id("value")).Op(":=").Range().Id("o").Dot("AdditionalProperties")).Block(
+				jen.If(jen.List(jen.Id("_"), jen.Id("exists")).Op(":=").Id("fields").Index(jen.Id("key")), jen.Id("exists")).Block(
+					jen.Continue(),
+				),
+				jen.List(jen.Id("raw"), jen.Err()).Op(":=").Qual(jsonPackage, "Marshal").Call(jen.Id("value")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+				jen.Id("fields").Index(jen.Id("key")).Op("=").Id("raw"),
+			),
+			jen.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("fields"))),
+		)
+
+	f.Commentf("UnmarshalJSON decodes the fixed properties of %s, and keeps the other ones as additional properties.", name)
+	f.Func().Params(jen.Id("o").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().
+		Block(
+			jen.Type().Id("known").Id(name),
+			jen.If(
+				jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Parens(jen.Op("*").Id("known")).Call(jen.Id("o"))),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Var().Id("fields").Map(jen.String()).Qual(jsonPackage, "RawMessage"),
+			jen.If(
+				jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("fields")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.For(jen.List(jen.Id("_"), jen.Id("key")).Op(":=").Range().Index().String().ValuesFunc(func(group *jen.Group) {
+				for _, name := range slices.Map(properties, func(in property) string { return in.name }) {
+					group.Lit(name)
+				}
+			})).Block(
+				jen.Delete(jen.Id("fields"), jen.Id("key")),
+			),
+			jen.Id("o").Dot("AdditionalProperties").Op("=").Nil(),
+			jen.For(jen.List(jen.Id("key"), jen.Id("raw")).Op(":=").Range().Id("fields")).Block(
+				jen.Var().Id("value").Add(valueType.Clone()),
+				jen.If(
+					jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("raw"), jen.Op("&").Id("value")),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err())),
+				jen.If(jen.Id("o").Dot("AdditionalProperties").Op("==").Nil()).Block(
+					jen.Id("o").Dot("AdditionalProperties").Op("=").Make(jen.Map(jen.String()).Add(valueType.Clone()), jen.Len(jen.Id("fields"))),
+				),
+				jen.Id("o").Dot("AdditionalProperties").Index(jen.Id("key")).Op("=").Id("value"),
+			),
+			jen.Return(jen.Nil()),
+		)
+	return nil
+}
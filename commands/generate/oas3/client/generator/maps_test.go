@@ -0,0 +1,11 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestMapsGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "maps", spec: "maps"},
+	})
+}
@@ -0,0 +1,599 @@
+package generator
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// mockOperation is an operation served by the generated mock, along with its canned response.
+type mockOperation struct {
+	methodName string
+	method     string
+	apiPath    string
+	pattern    string
+	// pathRE matches the paths of the operation when its pattern matches more paths, empty otherwise.
+	pathRE      string
+	status      int
+	contentType string
+	body        []byte
+	// bodyType is the type of the body of the successful response, nil when it has none.
+	bodyType *jen.Statement
+}
+
+// fakeStrings are the values of the fake strings, by format.
+var fakeStrings = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"ulid":      "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"phone":     "+15555550100",
+}
+
+// nodeValue converts a YAML node into the value it encodes as JSON, timestamps being kept as written.
+func nodeValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return nodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return nodeValue(node.Alias)
+	case yaml.SequenceNode:
+		return slices.Map(node.Content, nodeValue)
+	case yaml.MappingNode:
+		values := make(map[string]any, len(node.Content)/2)
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			values[node.Content[idx].Value] = nodeValue(node.Content[idx+1])
+		}
+		return values
+	}
+	switch node.Tag {
+	case "!!null":
+		return nil
+	case "!!bool":
+		value, err := strconv.ParseBool(node.Value)
+		if err == nil {
+			return value
+		}
+	case "!!int":
+		value, err := strconv.ParseInt(node.Value, 0, 64)
+		if err == nil {
+			return value
+		}
+	case "!!float":
+		value, err := strconv.ParseFloat(node.Value, 64)
+		if err == nil && !math.IsInf(value, 0) && !math.IsNaN(value) {
+			return value
+		}
+	}
+	return node.Value
+}
+
+// fakeValue returns a value of the schema, its example or default value when it documents one, fake data otherwise.
+// Properties and items referring to a schema being faked are left out, recursive schemas would never end otherwise.
+func (g *Generator) fakeValue(proxy *base.SchemaProxy, faking []string) (any, error) {
+	if proxy.IsReference() {
+		if slices.Contains(faking, proxy.GetReference()) {
+			return nil, nil
+		}
+		faking = append(faking[:len(faking):len(faking)], proxy.GetReference())
+	}
+	schema, err := proxy.BuildSchema()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schema %s", proxy.GetReference())
+	}
+	switch {
+	case schema.Example != nil:
+		return nodeValue(schema.Example), nil
+	case len(schema.Examples) > 0:
+		return nodeValue(schema.Examples[0]), nil
+	case schema.Default != nil:
+		return nodeValue(schema.Default), nil
+	case len(g.enumNodes(schema)) > 0:
+		return nodeValue(g.enumNodes(schema)[0]), nil
+	case len(schema.OneOf) > 0:
+		return g.fakeVariant(schema, schema.OneOf, faking)
+	case len(schema.AnyOf) > 0:
+		return g.fakeVariant(schema, schema.AnyOf, faking)
+	}
+
+	kind := g.schemaType(schema)
+	if kind == "" && (len(schema.AllOf) > 0 || schema.Properties != nil && schema.Properties.Len() > 0 || g.isConditionalObject(schema)) {
+		kind = "object"
+	}
+	switch kind {
+	case "string":
+		if value, exists := fakeStrings[schema.Format]; exists {
+			return value, nil
+		}
+		value := "string"
+		if schema.MinLength != nil && int(*schema.MinLength) > len(value) {
+			value += strings.Repeat("x", int(*schema.MinLength)-len(value))
+		}
+		return value, nil
+	case "integer":
+		if schema.Minimum != nil {
+			return int64(math.Ceil(*schema.Minimum)), nil
+		}
+		return 1, nil
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum, nil
+		}
+		return 1.5, nil
+	case "boolean":
+		return true, nil
+	case "array":
+		if schema.Items == nil || !schema.Items.IsA() {
+			return []any{}, nil
+		}
+		item, err := g.fakeValue(schema.Items.A, faking)
+		if err != nil || item == nil {
+			return []any{}, err
+		}
+		return []any{item}, nil
+	case "object":
+		return g.fakeObject(schema, faking)
+	}
+	return nil, nil
+}
+
+// fakeVariant returns a value of the first variant of a union. When the union has a discriminator, the first
+// referenced variant is picked instead, and its discriminator property is set to the value selecting it, so that
+// clients decoding the union recognize it.
+func (g *Generator) fakeVariant(schema *base.Schema, members []*base.SchemaProxy, faking []string) (any, error) {
+	if schema.Discriminator == nil {
+		return g.fakeValue(members[0], faking)
+	}
+	for _, member := range members {
+		values := discriminatorValues(schema.Discriminator, member)
+		if len(values) == 0 {
+			continue
+		}
+		value, err := g.fakeValue(member, faking)
+		if err != nil {
+			return nil, err
+		}
+		if object, ok := value.(map[string]any); ok {
+			object[schema.Discriminator.PropertyName] = values[0]
+		}
+		return value, nil
+	}
+	return g.fakeValue(members[0], faking)
+}
+
+// fakeObject returns an object holding a fake value for each of the properties of the schema, including the ones of
+// the schemas it is composed of, write only properties being left out since they are never part of responses.
+func (g *Generator) fakeObject(schema *base.Schema, faking []string) (map[string]any, error) {
+	object := make(map[string]any)
+	members := schema.AllOf
+	if g.isConditionalObject(schema) && schema.Then != nil {
+		members = append(members[:len(members):len(members)], schema.Then)
+	}
+	for _, member := range members {
+		value, err := g.fakeValue(member, faking)
+		if err != nil {
+			return nil, err
+		}
+		if values, ok := value.(map[string]any); ok {
+			for name, value := range values {
+				object[name] = value
+			}
+		}
+	}
+	if schema.Properties != nil {
+		for name := range schema.Properties.KeysFromOldest() {
+			propertyProxy := schema.Properties.Value(name)
+			propertySchema, err := propertyProxy.BuildSchema()
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid property schema %s", name)
+			}
+			if propertySchema.WriteOnly != nil && *propertySchema.WriteOnly {
+				continue
+			}
+			value, err := g.fakeValue(propertyProxy, faking)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil || slices.Contains(schema.Required, name) {
+				object[name] = value
+			}
+		}
+	}
+	if additional, ok := g.additionalProperties(schema); ok && len(object) == 0 && additional != nil {
+		value, err := g.fakeValue(additional, faking)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			object["key"] = value
+		}
+	}
+	return object, nil
+}
+
+// mockResponseContent returns the JSON content type of the response, and its media type.
+func mockResponseContent(response *v3.Response) (string, *v3.MediaType) {
+	if response == nil || response.Content == nil {
+		return "", nil
+	}
+	for contentType := range response.Content.KeysFromOldest() {
+		if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+			return contentType, response.Content.Value(contentType)
+		}
+	}
+	return "", nil
+}
+
+// mockBody returns the canned body of a response, from the example of the media type or the ones of its schema.
+func (g *Generator) mockBody(mediaType *v3.MediaType) ([]byte, error) {
+	var value any
+	switch {
+	case mediaType.Example != nil:
+		value = nodeValue(mediaType.Example)
+	case mediaType.Examples != nil && mediaType.Examples.Len() > 0:
+		for _, name := range orderedKeys(g.flags.Ordering, mediaType.Examples) {
+			if example := mediaType.Examples.Value(name); example != nil && example.Value != nil {
+				value = nodeValue(example.Value)
+				break
+			}
+		}
+	}
+	if value == nil && mediaType.Schema != nil {
+		var err error
+		if value, err = g.fakeValue(mediaType.Schema, nil); err != nil {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode example")
+	}
+	return body, nil
+}
+
+// mockPattern turns the path of an operation into a pattern of http.ServeMux, whose wildcards must be valid Go
+// identifiers spanning whole segments.
+func mockPattern(method, apiPath string) string {
+	segments := strings.Split(apiPath, "/")
+	for idx, segment := range segments {
+		if !strings.Contains(segment, "{") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") || strings.ContainsAny(name, "{}") {
+			name = "segment" + strconv.Itoa(idx)
+		}
+		name = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return '_'
+		}, name)
+		if name == "" || unicode.IsDigit(rune(name[0])) {
+			name = "_" + name
+		}
+		segments[idx] = "{" + name + "}"
+	}
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		// a trailing slash would otherwise match every path below it
+		pattern += "{$}"
+	}
+	return method + " " + pattern
+}
+
+// patternWildcardRE matches the wildcards of the patterns of http.ServeMux.
+var patternWildcardRE = regexp.MustCompile(`\{[^{}]*\}`)
+
+// pathParamRE matches the path parameters within a segment of a path.
+var pathParamRE = regexp.MustCompile(`\{[^{}/]*\}`)
+
+// mockPathRE returns the regular expression matching the paths of the operation when some of its path parameters do
+// not span whole segments, such as /nodes/{id}.json. Its pattern then also matches other paths, /nodes/{segment2} in
+// that case, which may be the ones of another operation. It returns an empty string otherwise.
+func mockPathRE(apiPath string) string {
+	partial := false
+	segments := strings.Split(apiPath, "/")
+	for idx, segment := range segments {
+		params := pathParamRE.FindAllStringIndex(segment, -1)
+		if len(params) == 0 {
+			segments[idx] = regexp.QuoteMeta(segment)
+			continue
+		}
+		if len(params) > 1 || params[0][0] != 0 || params[0][1] != len(segment) {
+			partial = true
+		}
+		var re strings.Builder
+		last := 0
+		for _, param := range params {
+			re.WriteString(regexp.QuoteMeta(segment[last:param[0]]))
+			re.WriteString("[^/]+")
+			last = param[1]
+		}
+		re.WriteString(regexp.QuoteMeta(segment[last:]))
+		segments[idx] = re.String()
+	}
+	if !partial {
+		return ""
+	}
+	return "^" + strings.Join(segments, "/") + "$"
+}
+
+// generateMockOperation returns how the mock serves the operation.
+func (g *Generator) generateMockOperation(method, apiPath string, operation *v3.Operation) (*mockOperation, error) {
+	log := logger.New().With(props.HttpMethod(method), props.HttpPath(apiPath))
+	methodName := g.methodName(operation)
+	status, response := successResponse(operation)
+	op := &mockOperation{
+		methodName: methodName,
+		method:     method,
+		apiPath:    apiPath,
+		pattern:    mockPattern(method, apiPath),
+		pathRE:     mockPathRE(apiPath),
+		status:     status,
+	}
+	contentType, mediaType := mockResponseContent(response)
+	if mediaType == nil {
+		if response != nil && response.Content != nil && response.Content.Len() > 0 {
+			log.Warn("replying without a body, the successful response has no JSON content")
+		}
+		return op, nil
+	}
+	body, err := g.mockBody(mediaType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate the response of %s %s", method, apiPath)
+	}
+	op.contentType, op.body = contentType, body
+	if mediaType.Schema != nil {
+		op.bodyType = jen.Null()
+		if err := g.schemaProxyToGoType(op.bodyType, mediaType.Schema, methodName+"Response"); err != nil {
+			return nil, errors.Wrapf(err, "invalid response type of %s %s", method, apiPath)
+		}
+	}
+	return op, nil
+}
+
+// generateMockHandler emits the `Handler` type routing the requests to the overrides or to the canned responses.
+func (g *Generator) generateMockHandler(f *jen.File) {
+	f.Comment("route is an operation of the API, along with its canned response. Its path is set when its pattern also matches")
+	f.Comment("paths which are not the ones of the operation.")
+	f.Type().Id("route").Struct(
+		jen.Id("operation").String(),
+		jen.Id("pattern").String(),
+		jen.Id("path").Op("*").Qual("regexp", "Regexp"),
+		jen.Id("status").Int(),
+		jen.Id("contentType").String(),
+		jen.Id("body").String(),
+	)
+
+	f.Comment("Handler is a fake implementation of the API, replying to every operation with the example of its successful")
+	f.Comment("response documented by the spec, or with fake data synthesized from its schema. The response of individual")
+	f.Comment("operations can be overridden by tests.")
+	f.Type().Id("Handler").Struct(
+		jen.Id("mux").Op("*").Qual("net/http", "ServeMux"),
+		jen.Id("mu").Qual("sync", "RWMutex"),
+		jen.Id("overrides").Map(jen.String()).Qual("net/http", "HandlerFunc"),
+	)
+
+	f.Comment("NewHandler returns a Handler replying with the canned responses of every operation.")
+	f.Func().Id("NewHandler").Params().Op("*").Id("Handler").Block(
+		jen.Id("h").Op(":=").Op("&").Id("Handler").Values(jen.Dict{
+			jen.Id("mux"):       jen.Qual("net/http", "NewServeMux").Call(),
+			jen.Id("overrides"): jen.Make(jen.Map(jen.String()).Qual("net/http", "HandlerFunc")),
+		}),
+		jen.For(jen.List(jen.Id("idx"), jen.Id("r")).Op(":=").Range().Id("routes")).Block(
+			jen.Comment("the routes sharing a pattern are told apart by their path when serving them"),
+			jen.If(jen.Qual("slices", "ContainsFunc").Call(jen.Id("routes").Index(jen.Empty(), jen.Id("idx")), jen.Func().Params(jen.Id("other").Id("route")).Bool().Block(
+				jen.Return(jen.Id("other").Dot("pattern").Op("==").Id("r").Dot("pattern")),
+			))).Block(jen.Continue()),
+			jen.Id("h").Dot("mux").Dot("HandleFunc").Call(jen.Id("r").Dot("pattern"), jen.Id("h").Dot("serve").Call(jen.Id("r").Dot("pattern"))),
+		),
+		jen.Return(jen.Id("h")),
+	)
+
+	f.Comment("NewServer starts a server replying with the canned responses of every operation, which must be closed once done.")
+	f.Func().Id("NewServer").Params().Parens(jen.List(jen.Op("*").Qual("net/http/httptest", "Server"), jen.Op("*").Id("Handler"))).Block(
+		jen.Id("h").Op(":=").Id("NewHandler").Call(),
+		jen.Return(jen.Qual("net/http/httptest", "NewServer").Call(jen.Id("h")), jen.Id("h")),
+	)
+
+	f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id("ServeHTTP").
+		Params(jen.Id("w").Qual("net/http", "ResponseWriter"), jen.Id("r").Op("*").Qual("net/http", "Request")).
+		Block(jen.Id("h").Dot("mux").Dot("ServeHTTP").Call(jen.Id("w"), jen.Id("r")))
+
+	f.Comment("Override replaces the canned response of the operation, named after the client method performing it, until")
+	f.Comment("Reset is called.")
+	f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id("Override").
+		Params(jen.Id("operation").String(), jen.Id("handler").Qual("net/http", "HandlerFunc")).
+		Block(
+			jen.If(jen.Op("!").Qual("slices", "ContainsFunc").Call(jen.Id("routes"), jen.Func().Params(jen.Id("r").Id("route")).Bool().Block(
+				jen.Return(jen.Id("r").Dot("operation").Op("==").Id("operation")),
+			))).Block(
+				jen.Panic(jen.Qual("fmt", "Sprintf").Call(jen.Lit("unknown operation %s"), jen.Id("operation"))),
+			),
+			jen.Id("h").Dot("mu").Dot("Lock").Call(),
+			jen.Defer().Id("h").Dot("mu").Dot("Unlock").Call(),
+			jen.Id("h").Dot("overrides").Index(jen.Id("operation")).Op("=").Id("handler"),
+		)
+
+	f.Comment("Reset restores the canned responses of every operation.")
+	f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id("Reset").Params().Block(
+		jen.Id("h").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("h").Dot("mu").Dot("Unlock").Call(),
+		jen.Id("h").Dot("overrides").Op("=").Make(jen.Map(jen.String()).Qual("net/http", "HandlerFunc")),
+	)
+
+	f.Comment("findRoute returns the route of the pattern matching the path, the routes whose path parameters do not span")
+	f.Comment("whole segments, such as /nodes/{id}.json, taking precedence over the ones sharing their pattern, such as")
+	f.Comment("/nodes/{id}.")
+	f.Func().Id("findRoute").Params(jen.List(jen.Id("pattern"), jen.Id("path")).String()).Parens(jen.List(jen.Id("route"), jen.Bool())).Block(
+		jen.Var().Id("found").Id("route"),
+		jen.Id("ok").Op(":=").False(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("r")).Op(":=").Range().Id("routes")).Block(
+			jen.Switch().Block(
+				jen.Case(jen.Id("r").Dot("pattern").Op("!=").Id("pattern")).Block(jen.Continue()),
+				jen.Case(jen.Id("r").Dot("path").Op("!=").Nil()).Block(
+					jen.If(jen.Id("r").Dot("path").Dot("MatchString").Call(jen.Id("path"))).Block(
+						jen.Return(jen.Id("r"), jen.True()),
+					),
+				),
+				jen.Case(jen.Op("!").Id("ok")).Block(
+					jen.List(jen.Id("found"), jen.Id("ok")).Op("=").List(jen.Id("r"), jen.True()),
+				),
+			),
+		),
+		jen.Return(jen.Id("found"), jen.Id("ok")),
+	)
+
+	f.Comment("serve replies to the requests of the routes of the pattern with their override, or with their canned response.")
+	f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id("serve").Params(jen.Id("pattern").String()).Qual("net/http", "HandlerFunc").Block(
+		jen.Return(jen.Func().Params(jen.Id("w").Qual("net/http", "ResponseWriter"), jen.Id("req").Op("*").Qual("net/http", "Request")).Block(
+			jen.List(jen.Id("r"), jen.Id("ok")).Op(":=").Id("findRoute").Call(jen.Id("pattern"), jen.Id("req").Dot("URL").Dot("Path")),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.Qual("net/http", "NotFound").Call(jen.Id("w"), jen.Id("req")),
+				jen.Return(),
+			),
+			jen.Id("h").Dot("mu").Dot("RLock").Call(),
+			jen.Id("override").Op(":=").Id("h").Dot("overrides").Index(jen.Id("r").Dot("operation")),
+			jen.Id("h").Dot("mu").Dot("RUnlock").Call(),
+			jen.If(jen.Id("override").Op("!=").Nil()).Block(
+				jen.Id("override").Call(jen.Id("w"), jen.Id("req")),
+				jen.Return(),
+			),
+			jen.If(jen.Id("r").Dot("contentType").Op("!=").Lit("")).Block(
+				jen.Id("w").Dot("Header").Call().Dot("Set").Call(jen.Lit("Content-Type"), jen.Id("r").Dot("contentType")),
+			),
+			jen.Id("w").Dot("WriteHeader").Call(jen.Id("r").Dot("status")),
+			jen.List(jen.Id("_"), jen.Id("_")).Op("=").Qual("io", "WriteString").Call(jen.Id("w"), jen.Id("r").Dot("body")),
+		)),
+	)
+
+	f.Comment("writeJSON replies with the status code and the JSON encoding of the body.")
+	f.Func().Id("writeJSON").Params(jen.Id("w").Qual("net/http", "ResponseWriter"), jen.Id("status").Int(), jen.Id("body").Any()).Block(
+		jen.List(jen.Id("encoded"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("body")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Qual("net/http", "Error").Call(jen.Id("w"), jen.Err().Dot("Error").Call(), jen.Qual("net/http", "StatusInternalServerError")),
+			jen.Return(),
+		),
+		jen.Id("w").Dot("Header").Call().Dot("Set").Call(jen.Lit("Content-Type"), jen.Lit("application/json")),
+		jen.Id("w").Dot("WriteHeader").Call(jen.Id("status")),
+		jen.List(jen.Id("_"), jen.Id("_")).Op("=").Id("w").Dot("Write").Call(jen.Id("encoded")),
+	)
+}
+
+// generateMockHook emits the typed `On<Method>` hook overriding the response of the operation.
+func generateMockHook(f *jen.File, op *mockOperation) {
+	hook := "On" + op.methodName
+	request := jen.Id("r").Op("*").Qual("net/http", "Request")
+	if op.bodyType == nil {
+		f.Commentf("%s overrides the response of the %s operation with the status code returned by fn.", hook, op.methodName)
+		f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id(hook).
+			Params(jen.Id("fn").Func().Params(request.Clone()).Int()).
+			Block(jen.Id("h").Dot("Override").Call(jen.Lit(op.methodName), jen.Func().
+				Params(jen.Id("w").Qual("net/http", "ResponseWriter"), request.Clone()).
+				Block(jen.Id("w").Dot("WriteHeader").Call(jen.Id("fn").Call(jen.Id("r"))))))
+		return
+	}
+	f.Commentf("%s overrides the response of the %s operation with the status code and the body returned by fn.", hook, op.methodName)
+	f.Func().Params(jen.Id("h").Op("*").Id("Handler")).Id(hook).
+		Params(jen.Id("fn").Func().Params(request.Clone()).Parens(jen.List(jen.Int(), op.bodyType.Clone()))).
+		Block(jen.Id("h").Dot("Override").Call(jen.Lit(op.methodName), jen.Func().
+			Params(jen.Id("w").Qual("net/http", "ResponseWriter"), request.Clone()).
+			Block(
+				jen.List(jen.Id("status"), jen.Id("body")).Op(":=").Id("fn").Call(jen.Id("r")),
+				jen.Id("writeJSON").Call(jen.Id("w"), jen.Id("status"), jen.Id("body")),
+			)))
+}
+
+func (g *Generator) generateMockPackage(document v3.Document) error {
+	// the mock lives next to the `dtos` package, so that a client generated into the same output directory shares it
+	f := g.generatePackageFile(g.flags.PackageName, g.flags.PackageName, "mock")
+	routes := g.generatePackageFile(g.flags.PackageName, g.flags.PackageName, "routes")
+
+	operations := make([]*mockOperation, 0)
+	for _, apiPath := range orderedKeys(g.flags.Ordering, document.Paths.PathItems) {
+		pathItem := document.Paths.PathItems.Value(apiPath)
+		for _, op := range pathOperations(pathItem) {
+			if !g.isOperationSelected(apiPath, op.operation) {
+				continue
+			}
+			generated, err := g.generateMockOperation(op.method, apiPath, op.operation)
+			if err != nil {
+				return errors.Wrapf(err, "failed to generate mock %s operation for %s", op.method, apiPath)
+			}
+			operations = append(operations, generated)
+		}
+	}
+
+	// patterns differing by the names of their wildcards match the same requests, which http.ServeMux refuses, the
+	// operations share a single pattern instead, preferably the one of an operation whose wildcards are its path
+	// parameters, so that their values are found under their name
+	patterns := make(map[string]string)
+	for _, partial := range []bool{false, true} {
+		for _, op := range operations {
+			if (op.pathRE != "") != partial {
+				continue
+			}
+			shape := patternWildcardRE.ReplaceAllString(op.pattern, "{}")
+			if pattern, exists := patterns[shape]; exists {
+				op.pattern = pattern
+			} else {
+				patterns[shape] = op.pattern
+			}
+		}
+	}
+
+	g.generateMockHandler(f)
+	for _, op := range operations {
+		generateMockHook(f, op)
+	}
+
+	routes.Comment("routes lists the operations of the API, along with their canned responses.")
+	routes.Var().Id("routes").Op("=").Index().Id("route").ValuesFunc(func(group *jen.Group) {
+		for _, op := range operations {
+			values := jen.Dict{
+				jen.Id("operation"): jen.Lit(op.methodName),
+				jen.Id("pattern"):   jen.Lit(op.pattern),
+				jen.Id("status"):    jen.Lit(op.status),
+			}
+			if op.pathRE != "" {
+				values[jen.Id("path")] = jen.Qual("regexp", "MustCompile").Call(jen.Id("`" + op.pathRE + "`"))
+			}
+			if op.contentType != "" {
+				values[jen.Id("contentType")] = jen.Lit(op.contentType)
+				body := jen.Lit(string(op.body))
+				if !strings.Contains(string(op.body), "`") {
+					// raw strings keep the JSON readable
+					body = jen.Id("`" + string(op.body) + "`")
+				}
+				values[jen.Id("body")] = body
+			}
+			group.Values(values)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,18 @@
+package generator
+
+import (
+	"testing"
+)
+
+var mockCase = goldenCase{name: "petstore_mock", spec: "petstore", flags: func(flags *Flags) { flags.Target = TargetMock }}
+
+func TestMockGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		mockCase,
+		{name: "examples_mock", spec: "examples", flags: func(flags *Flags) { flags.Target = TargetMock }},
+	})
+}
+
+func TestMock(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "examples_mock", spec: "examples", flags: func(flags *Flags) { flags.Target = TargetMock }}, "mock")
+}
@@ -1,11 +1,19 @@
 package generator
 
 import (
+	"context"
+	"go/token"
 	"os"
 	"path"
+	"regexp"
+	"runtime/debug"
+	"strings"
 	"text/template"
 
+	"golang.org/x/mod/module"
+
 	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
 )
 
 const goModTemplateText = `module {{ .ModuleName }}
@@ -31,17 +39,23 @@ type Import struct {
 	Version string
 }
 
-func (g *Generator) generateModule() error {
-	moduleName := g.moduleName
+// generateModule writes the go.mod file of the generated module, requiring the modules imported by the rendered files,
+// and tidies it.
+func (g *Generator) generateModule(ctx context.Context, rendered map[string][]byte) error {
+	goVersion, err := g.moduleGoVersion()
+	if err != nil {
+		return err
+	}
+	requirements, err := g.moduleRequirements(rendered)
+	if err != nil {
+		return err
+	}
+	g.imports = slices.Map(requirements, func(in *debug.Module) Import {
+		return Import{Package: in.Path, Version: in.Version}
+	})
 	goModFile, err := os.Create(path.Join(g.outputDir, "go.mod"))
 	if err != nil {
-		if !errors.Is(err, os.ErrExist) {
-			return errors.Wrapf(err, "failed to create go.mod file")
-		}
-		goModFile, err = os.OpenFile(path.Join(g.outputDir, "go.mod"), os.O_WRONLY, 0644)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open go.mod file")
-		}
+		return errors.Wrapf(err, "failed to create go.mod file")
 	}
 	defer func(goModFile *os.File) {
 		err := goModFile.Close()
@@ -51,12 +65,50 @@ func (g *Generator) generateModule() error {
 	}(goModFile)
 
 	err = goModTemplate.Execute(goModFile, goModTemplateArgs{
-		ModuleName: moduleName,
-		GoVersion:  "1.23.0",
+		ModuleName: g.moduleName,
+		GoVersion:  goVersion,
 		Imports:    g.imports,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to execute go.mod template")
 	}
+	if err := seedGoSum(path.Join(g.outputDir, "go.sum"), requirements); err != nil {
+		return err
+	}
+	return g.tidyModule(ctx)
+}
+
+// invalidModulePathChars matches the runs of characters which are not allowed in a module path, or would be
+// surprising in one.
+var invalidModulePathChars = regexp.MustCompile(`[^a-z0-9._~/-]+`)
+
+// sanitizeModulePath derives a module path from the title of the spec, so that "Payments API v2" becomes
+// `payments-api-v2`.
+func sanitizeModulePath(title string) string {
+	modulePath := invalidModulePathChars.ReplaceAllString(strings.ToLower(title), "-")
+	segments := strings.Split(modulePath, "/")
+	for idx, segment := range segments {
+		segments[idx] = strings.Trim(segment, "-.~")
+	}
+	modulePath = strings.Join(slices.Filter(segments, func(segment string) bool { return segment != "" }), "/")
+	if modulePath == "" {
+		return "client"
+	}
+	return modulePath
+}
+
+// validateModulePath checks the module path against the rules of the go command.
+func validateModulePath(modulePath string) error {
+	if err := module.CheckImportPath(modulePath); err != nil {
+		return errors.Wrapf(err, "invalid module path %s", modulePath)
+	}
+	return nil
+}
+
+// validatePackageName checks that the package name is a valid Go identifier.
+func validatePackageName(name string) error {
+	if !token.IsIdentifier(name) || name == "_" {
+		return errors.Newf("invalid package name %s, it must be a valid Go identifier", name)
+	}
 	return nil
 }
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeModulePath(t *testing.T) {
+	cases := []struct {
+		title      string
+		modulePath string
+	}{
+		{title: "Payments API v2", modulePath: "payments-api-v2"},
+		{title: "Acme / Billing API", modulePath: "acme/billing-api"},
+		{title: "github.com/Acme/Pets", modulePath: "github.com/acme/pets"},
+		{title: "  ---  ", modulePath: "client"},
+		{title: "", modulePath: "client"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			modulePath := sanitizeModulePath(tc.title)
+			require.Equal(t, tc.modulePath, modulePath)
+			require.NoError(t, validateModulePath(modulePath))
+		})
+	}
+}
+
+func TestModuleAndPackageNames(t *testing.T) {
+	runCompileCases(t, []goldenCase{
+		{name: "package_names", spec: "petstore", flags: func(flags *Flags) {
+			flags.PackageName = "payments"
+			flags.DTOsPackageName = "models"
+		}},
+	})
+
+	t.Run("title", func(t *testing.T) {
+		flags := DefaultFlags()
+		flags.OutputDir = t.TempDir()
+		flags.GenerateModule = false
+		generateSpec(t, "petstore", flags)
+		client, err := os.ReadFile(filepath.Join(flags.OutputDir, "client.go"))
+		require.NoError(t, err)
+		require.Contains(t, string(client), `"petstore/dtos"`, "the module path defaults to the title of the spec")
+	})
+
+	invalid := []struct {
+		name  string
+		flags func(flags *Flags)
+		err   string
+	}{
+		{name: "module path", flags: func(flags *Flags) { flags.ModuleName = "Payments API" }, err: "invalid module path"},
+		{name: "package name", flags: func(flags *Flags) { flags.PackageName = "my-client" }, err: "invalid package name my-client"},
+		{name: "dtos package name", flags: func(flags *Flags) { flags.DTOsPackageName = "type" }, err: "invalid package name type"},
+		{name: "same package names", flags: func(flags *Flags) { flags.DTOsPackageName = "client" }, err: "must have different names"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			flags := DefaultFlags()
+			flags.OutputDir = t.TempDir()
+			flags.GenerateModule = false
+			tc.flags(&flags)
+			err := buildSpec(t, "petstore", flags)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+			entries, err := os.ReadDir(flags.OutputDir)
+			require.NoError(t, err)
+			require.Empty(t, entries, "nothing is written with invalid names")
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/chanced/caps"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// methodNameExtension overrides the name of the method generated for an operation.
+const methodNameExtension = "x-go-name"
+
+// reservedNames lists the names the methods and groups cannot use, as they would collide with the fields and methods
+// of the generated clients and mocks, or with the types generated next to them.
+var reservedNames = []string{"Client", "Request", "Mock", "Calls", "CallsTo", "Reset"}
+
+// reservedParamNames lists the identifiers used by the body of the generated client methods, which path parameters
+// cannot shadow.
+var reservedParamNames = []string{
+	"c", "ctx", "path", "body", "bodyOpts", "params", "opts", "request", "response", "err",
+	"context", "fmt", "errors", "opt", "sdk",
+}
+
+// goIdentifier turns an arbitrary name, such as an operation id or a path segment, into an exported Go identifier,
+// so that `list-pets` becomes `ListPets`. Identifiers starting with a digit are given the prefix. It returns an empty
+// string when the name holds no letter nor digit.
+func goIdentifier(name, prefix string) string {
+	fragments := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	identifier := strings.Join(slices.Map(fragments, func(in string) string { return caps.ToCamel(in) }), "")
+	if identifier != "" && !unicode.IsLetter([]rune(identifier)[0]) {
+		identifier = prefix + identifier
+	}
+	return identifier
+}
+
+// goParamName returns the name of a method parameter, suffixed when it is a Go keyword or a reserved identifier.
+func goParamName(name string) string {
+	if token.IsKeyword(name) || slices.Contains(reservedParamNames, name) {
+		return name + "Param"
+	}
+	return name
+}
+
+// methodNameFromOperation derives the name of the method of an operation from its id, falling back to its http
+// method and path when it has none.
+func methodNameFromOperation(method, apiPath string, operation *v3.Operation) string {
+	if name := goIdentifier(operation.OperationId, "Op"); name != "" {
+		return name
+	}
+	return methodNameFromPath(method, apiPath)
+}
+
+// methodNameFromPath derives the name of the method of an operation from its http method and path, so that
+// `GET /users/{id}/posts` becomes `GetUsersByIdPosts`.
+func methodNameFromPath(method, apiPath string) string {
+	name := caps.ToCamel(strings.ToLower(method))
+	segments := pathSegments(apiPath)
+	if len(segments) == 0 {
+		return name + "Root"
+	}
+	for _, segment := range segments {
+		if isPathParam(segment) {
+			name += "By" + goIdentifier(strings.Trim(segment, "{}"), "")
+		} else {
+			name += goIdentifier(segment, "")
+		}
+	}
+	return name
+}
+
+// namedOperation is an operation along with the path and http method it is declared for.
+type namedOperation struct {
+	method    string
+	apiPath   string
+	operation *v3.Operation
+}
+
+// explicitMethodName returns the name given to the operation by the MethodNames flag or its `x-go-name` extension, if
+// any.
+func (g *Generator) explicitMethodName(operation *v3.Operation) string {
+	if name, exists := g.flags.MethodNames[operation.OperationId]; exists && operation.OperationId != "" {
+		return name
+	}
+	return extensionString(operation.Extensions, methodNameExtension)
+}
+
+// assignMethodNames names the methods of the selected operations of the document, so that every target uses the same
+// names. Explicit names must be valid and unique, while the derived ones fall back to the http method and path of
+// the operation, then to a numeric suffix, when they collide.
+func (g *Generator) assignMethodNames(document v3.Document) error {
+	log := logger.New()
+	g.methodNames = make(map[*v3.Operation]string)
+	used := make(map[string]string)
+	// collision describes what the name collides with, if anything. The mocks hold a `<Method>Func` field next to each
+	// method, which must not collide with another method either.
+	collision := func(name string) string {
+		switch {
+		case slices.Contains(reservedNames, name):
+			return "a field or method of the generated clients"
+		case used[name] != "":
+			return used[name]
+		case used[name+"Func"] != "":
+			return "the mock function of " + used[name+"Func"]
+		case strings.HasSuffix(name, "Func") && used[strings.TrimSuffix(name, "Func")] != "":
+			return "the mock function of " + used[strings.TrimSuffix(name, "Func")]
+		}
+		return ""
+	}
+
+	var operations []namedOperation
+	apiPaths := orderedKeys(OrderingSorted, document.Paths.PathItems)
+	for _, apiPath := range apiPaths {
+		for _, op := range pathOperations(document.Paths.PathItems.Value(apiPath)) {
+			if g.isOperationSelected(apiPath, op.operation) {
+				operations = append(operations, namedOperation{method: op.method, apiPath: apiPath, operation: op.operation})
+			}
+		}
+	}
+
+	for _, op := range operations {
+		name := g.explicitMethodName(op.operation)
+		if name == "" {
+			continue
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return errors.Newf("invalid method name %s for %s %s, it must be an exported Go identifier", name, op.method, op.apiPath)
+		}
+		if owner := collision(name); owner != "" {
+			return errors.Newf("method name %s of %s %s collides with %s", name, op.method, op.apiPath, owner)
+		}
+		used[name] = op.method + " " + op.apiPath
+		g.methodNames[op.operation] = name
+	}
+
+	for _, op := range operations {
+		if _, named := g.methodNames[op.operation]; named {
+			continue
+		}
+		candidates := slices.Of(methodNameFromOperation(op.method, op.apiPath, op.operation), methodNameFromPath(op.method, op.apiPath))
+		name := ""
+		for _, candidate := range candidates {
+			if collision(candidate) == "" {
+				name = candidate
+				break
+			}
+		}
+		for idx := 2; name == ""; idx++ {
+			if candidate := fmt.Sprintf("%s%d", candidates[0], idx); collision(candidate) == "" {
+				name = candidate
+			}
+		}
+		if name != candidates[0] {
+			log.Warn("renamed colliding method, use x-go-name or a method name mapping to pick its name",
+				props.HttpMethod(op.method), props.HttpPath(op.apiPath),
+				zap.String("method", candidates[0]), zap.String("renamed", name), zap.String("collides.with", collision(candidates[0])))
+		}
+		used[name] = op.method + " " + op.apiPath
+		g.methodNames[op.operation] = name
+	}
+	return nil
+}
+
+// methodName returns the name of the method generated for the operation, as assigned by assignMethodNames.
+func (g *Generator) methodName(operation *v3.Operation) string {
+	return g.methodNames[operation]
+}
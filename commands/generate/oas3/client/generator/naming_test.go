@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamingGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "naming", spec: "naming"},
+		{name: "component_names", spec: "component_names"},
+	})
+}
+
+// TestEveryOperationIsNamed checks that the server and the mock serve the operations without operation id nor tag,
+// which are named after their method and path like the client methods performing them.
+func TestEveryOperationIsNamed(t *testing.T) {
+	cases := []struct {
+		target   string
+		filename string
+		expected []string
+	}{
+		{target: TargetServer, filename: "server/server.go", expected: []string{"func NewGetRootHandler(", "func NewGetUsersByTypeItemsByCtxHandler("}},
+		{target: TargetMock, filename: "mock/routes.go", expected: []string{`operation:   "GetRoot",`, `operation:   "GetUsersByTypeItemsByCtx",`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			flags := goldenCase{name: "naming_" + tc.target, spec: "naming", flags: func(flags *Flags) { flags.Target = tc.target }}.generate(t)
+			content, err := os.ReadFile(filepath.Join(flags.OutputDir, filepath.FromSlash(tc.filename)))
+			require.NoError(t, err)
+			for _, expected := range tc.expected {
+				require.True(t, strings.Contains(string(content), expected), "%s lacks %s", tc.filename, expected)
+			}
+			runGo(t, flags.OutputDir, flags.ModuleName, "vet", "./...")
+		})
+	}
+}
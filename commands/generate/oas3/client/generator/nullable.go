@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"path"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+const (
+	// OptionalStylePointer generates optional and nullable properties as pointers, which cannot distinguish an absent
+	// property from a null one.
+	OptionalStylePointer = "pointer"
+	// OptionalStyleNullable generates optional and nullable properties with the generic `Optional[T]` and
+	// `Nullable[T]` types of the `dtos` package, keeping track of all three states.
+	OptionalStyleNullable = "nullable"
+)
+
+// propertyType writes the Go type of a struct property into stmt, wrapping it according to the optional style,
+// and returns the options of its json tag.
+func (g *Generator) propertyType(stmt *jen.Statement, p property, schema *base.Schema, name string) ([]string, error) {
+	valueType := jen.Null()
+	if err := g.oas3TypeToGoType(valueType, p.proxy, schema, name); err != nil {
+		return nil, err
+	}
+	nullable := g.isNullableSchema(schema)
+	if p.required && !nullable {
+		stmt.Add(valueType)
+		return nil, nil
+	}
+	if g.flags.OptionalStyle == OptionalStyleNullable {
+		g.generateNullableTypes()
+		wrapper := "Nullable"
+		if !nullable {
+			wrapper = "Optional"
+		}
+		stmt.Qual(g.dtoPackage(), wrapper).Types(valueType)
+		if p.required {
+			return nil, nil
+		}
+		return []string{"omitzero"}, nil
+	}
+	// slices, maps and untyped values are already nil-able, tuples are structs which `omitempty` never omits
+	kind := g.schemaType(schema)
+	if (kind != "array" || g.isTupleSchema(schema)) && !g.isDictionarySchema(schema) && (kind != "" || g.isNamedSchema(schema)) {
+		stmt.Op("*")
+	}
+	stmt.Add(valueType)
+	if p.required {
+		return nil, nil
+	}
+	return []string{"omitempty"}, nil
+}
+
+// generateNullableTypes emits the generic `Optional[T]` and `Nullable[T]` types into the `dtos` package, once.
+func (g *Generator) generateNullableTypes() {
+	if _, exists := g.files[path.Join(g.flags.DTOsPackageName, "nullable.go")]; exists {
+		return
+	}
+	// `omitzero` tags are only honoured starting with go 1.24
+	g.goVersion = "1.24.0"
+	f := g.generatePackageFile(g.flags.DTOsPackageName, g.flags.DTOsPackageName, "nullable")
+	jsonPackage := "encoding/json"
+	typeParams := jen.Id("T").Any()
+
+	f.Comment("Optional holds a value that may be absent, absent values are omitted when marshalling.")
+	f.Type().Id("Optional").Types(typeParams.Clone()).Struct(
+		jen.Id("value").Id("T"),
+		jen.Id("set").Bool(),
+	)
+	f.Comment("NewOptional returns an Optional holding the given value.")
+	f.Func().Id("NewOptional").Types(typeParams.Clone()).Params(jen.Id("value").Id("T")).Id("Optional").Index(jen.Id("T")).Block(
+		jen.Return(jen.Id("Optional").Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("value"): jen.Id("value"),
+			jen.Id("set"):   jen.True(),
+		})),
+	)
+	f.Comment("Get returns the value, and whether it is present.")
+	f.Func().Params(jen.Id("o").Id("Optional").Index(jen.Id("T"))).Id("Get").Params().Parens(jen.List(jen.Id("T"), jen.Bool())).Block(
+		jen.Return(jen.Id("o").Dot("value"), jen.Id("o").Dot("set")),
+	)
+	f.Comment("IsZero reports whether the value is absent.")
+	f.Func().Params(jen.Id("o").Id("Optional").Index(jen.Id("T"))).Id("IsZero").Params().Bool().Block(
+		jen.Return(jen.Op("!").Id("o").Dot("set")),
+	)
+	f.Func().Params(jen.Id("o").Id("Optional").Index(jen.Id("T"))).Id("MarshalJSON").Params().Parens(jen.List(jen.Index().Byte(), jen.Error())).Block(
+		jen.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("o").Dot("value"))),
+	)
+	f.Func().Params(jen.Id("o").Op("*").Id("Optional").Index(jen.Id("T"))).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().Block(
+		jen.Id("o").Dot("set").Op("=").True(),
+		jen.Return(jen.Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("o").Dot("value"))),
+	)
+	if len(g.formSchemas) > 0 {
+		f.Comment("EncodeValues encodes the value into url encoded forms, absent values are omitted.")
+		f.Func().Params(jen.Id("o").Id("Optional").Index(jen.Id("T"))).Id("EncodeValues").
+			Params(jen.Id("key").String(), jen.Id("values").Op("*").Qual("net/url", "Values")).Error().Block(
+			jen.If(jen.Id("o").Dot("set")).Block(
+				jen.Id("values").Dot("Add").Call(jen.Id("key"), jen.Qual("fmt", "Sprint").Call(jen.Id("o").Dot("value"))),
+			),
+			jen.Return(jen.Nil()),
+		)
+	}
+
+	f.Comment("Nullable holds a value that may be absent, null or set. Absent values are omitted when marshalling")
+	f.Comment("optional properties, and marshalled as null otherwise.")
+	f.Type().Id("Nullable").Types(typeParams.Clone()).Struct(
+		jen.Id("value").Id("T"),
+		jen.Id("valid").Bool(),
+		jen.Id("set").Bool(),
+	)
+	f.Comment("NewNullable returns a Nullable holding the given value.")
+	f.Func().Id("NewNullable").Types(typeParams.Clone()).Params(jen.Id("value").Id("T")).Id("Nullable").Index(jen.Id("T")).Block(
+		jen.Return(jen.Id("Nullable").Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("value"): jen.Id("value"),
+			jen.Id("valid"): jen.True(),
+			jen.Id("set"):   jen.True(),
+		})),
+	)
+	f.Comment("Null returns a Nullable explicitly set to null.")
+	f.Func().Id("Null").Types(typeParams.Clone()).Params().Id("Nullable").Index(jen.Id("T")).Block(
+		jen.Return(jen.Id("Nullable").Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("set"): jen.True(),
+		})),
+	)
+	f.Comment("Get returns the value, and whether it is present and not null.")
+	f.Func().Params(jen.Id("n").Id("Nullable").Index(jen.Id("T"))).Id("Get").Params().Parens(jen.List(jen.Id("T"), jen.Bool())).Block(
+		jen.Return(jen.Id("n").Dot("value"), jen.Id("n").Dot("valid")),
+	)
+	f.Comment("IsNull reports whether the value was explicitly set to null.")
+	f.Func().Params(jen.Id("n").Id("Nullable").Index(jen.Id("T"))).Id("IsNull").Params().Bool().Block(
+		jen.Return(jen.Id("n").Dot("set").Op("&&").Op("!").Id("n").Dot("valid")),
+	)
+	f.Comment("IsZero reports whether the value is absent.")
+	f.Func().Params(jen.Id("n").Id("Nullable").Index(jen.Id("T"))).Id("IsZero").Params().Bool().Block(
+		jen.Return(jen.Op("!").Id("n").Dot("set")),
+	)
+	f.Func().Params(jen.Id("n").Id("Nullable").Index(jen.Id("T"))).Id("MarshalJSON").Params().Parens(jen.List(jen.Index().Byte(), jen.Error())).Block(
+		jen.If(jen.Op("!").Id("n").Dot("valid")).Block(
+			jen.Return(jen.Index().Byte().Parens(jen.Lit("null")), jen.Nil()),
+		),
+		jen.Return(jen.Qual(jsonPackage, "Marshal").Call(jen.Id("n").Dot("value"))),
+	)
+	f.Func().Params(jen.Id("n").Op("*").Id("Nullable").Index(jen.Id("T"))).Id("UnmarshalJSON").Params(jen.Id("data").Index().Byte()).Error().Block(
+		jen.Op("*").Id("n").Op("=").Id("Nullable").Index(jen.Id("T")).Values(jen.Dict{jen.Id("set"): jen.True()}),
+		jen.If(jen.String().Parens(jen.Id("data")).Op("==").Lit("null")).Block(jen.Return(jen.Nil())),
+		jen.If(
+			jen.Err().Op(":=").Qual(jsonPackage, "Unmarshal").Call(jen.Id("data"), jen.Op("&").Id("n").Dot("value")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.Id("n").Dot("valid").Op("=").True(),
+		jen.Return(jen.Nil()),
+	)
+	if len(g.formSchemas) > 0 {
+		f.Comment("EncodeValues encodes the value into url encoded forms, absent values are omitted and null ones are empty.")
+		f.Func().Params(jen.Id("n").Id("Nullable").Index(jen.Id("T"))).Id("EncodeValues").
+			Params(jen.Id("key").String(), jen.Id("values").Op("*").Qual("net/url", "Values")).Error().BlockFunc(func(group *jen.Group) {
+			group.Switch().Block(
+				jen.Case(jen.Op("!").Id("n").Dot("set")).Block(),
+				jen.Case(jen.Op("!").Id("n").Dot("valid")).Block(jen.Id("values").Dot("Add").Call(jen.Id("key"), jen.Lit(""))),
+				jen.Default().Block(
+					jen.Id("values").Dot("Add").Call(jen.Id("key"), jen.Qual("fmt", "Sprint").Call(jen.Id("n").Dot("value"))),
+				),
+			)
+			group.Return(jen.Nil())
+		})
+	}
+}
+
+// validateOptionalStyle checks the optional style flag, defaulting it to OptionalStylePointer.
+func validateOptionalStyle(style string) (string, error) {
+	switch style {
+	case "":
+		return OptionalStylePointer, nil
+	case OptionalStylePointer, OptionalStyleNullable:
+		return style, nil
+	default:
+		return "", errors.Newf("unsupported optional style %s, expected one of %s or %s", style, OptionalStylePointer, OptionalStyleNullable)
+	}
+}
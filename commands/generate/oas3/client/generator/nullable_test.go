@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"testing"
+)
+
+func nullableStyle(flags *Flags) {
+	flags.OptionalStyle = OptionalStyleNullable
+}
+
+func TestNullableGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "nullable", spec: "nullable"},
+		{name: "nullable_optional_style", spec: "nullable", flags: nullableStyle},
+	})
+}
+
+func TestOptionalStylesCompile(t *testing.T) {
+	var cases []goldenCase
+	for _, target := range []string{TargetClient, TargetMock} {
+		for _, style := range []string{OptionalStylePointer, OptionalStyleNullable} {
+			cases = append(cases, goldenCase{
+				name: target + "_" + style,
+				spec: "petstore",
+				flags: func(flags *Flags) {
+					flags.Target = target
+					flags.OptionalStyle = style
+				},
+			})
+		}
+	}
+	runCompileCases(t, cases)
+}
+
+func TestNullableRoundTrip(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "nullable", spec: "nullable", flags: nullableStyle}, "nullable")
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+const (
+	// OrderingSorted generates types, properties and methods sorted by name, so that reordering the spec does not
+	// change the generated code.
+	OrderingSorted = "sorted"
+	// OrderingSpec generates types, properties and methods in the order they are declared in the spec.
+	OrderingSpec = "spec"
+)
+
+// validateOrdering checks the ordering flag, defaulting it to OrderingSorted.
+func validateOrdering(ordering string) (string, error) {
+	switch ordering {
+	case "":
+		return OrderingSorted, nil
+	case OrderingSorted, OrderingSpec:
+		return ordering, nil
+	default:
+		return "", errors.Newf("unsupported ordering %s, expected one of %s or %s", ordering, OrderingSorted, OrderingSpec)
+	}
+}
+
+// orderedKeys returns the keys of the map, either sorted or in the order of the spec.
+func orderedKeys[V any](ordering string, m *orderedmap.Map[string, V]) []string {
+	keys := make([]string, 0, m.Len())
+	for key := range m.KeysFromOldest() {
+		keys = append(keys, key)
+	}
+	if ordering == OrderingSorted {
+		sort.Strings(keys)
+	}
+	return keys
+}
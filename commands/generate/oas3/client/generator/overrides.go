@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// OperationFilter matches operations by tag, path or operation id.
+type OperationFilter struct {
+	// Tags matches the operations having any of these tags.
+	Tags []string `yaml:"tags"`
+	// Paths matches the operations of these paths, which are `path.Match` patterns such as `/admin/*`.
+	Paths []string `yaml:"paths"`
+	// Operations matches the operations having any of these operation ids.
+	Operations []string `yaml:"operations"`
+}
+
+// IsEmpty reports whether the filter matches nothing.
+func (f OperationFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && len(f.Paths) == 0 && len(f.Operations) == 0
+}
+
+// Matches reports whether the filter matches the operation of the given path.
+func (f OperationFilter) Matches(apiPath string, operation *v3.Operation) bool {
+	if operation.OperationId != "" && slices.Contains(f.Operations, operation.OperationId) {
+		return true
+	}
+	for _, tag := range operation.Tags {
+		if slices.Contains(f.Tags, tag) {
+			return true
+		}
+	}
+	for _, pattern := range f.Paths {
+		if matched, _ := path.Match(pattern, apiPath); matched || pattern == apiPath {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOperationFilter makes sure the path patterns of the filter are well-formed.
+func validateOperationFilter(filter OperationFilter) error {
+	for _, pattern := range filter.Paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid path pattern %s", pattern)
+		}
+	}
+	return nil
+}
+
+// validateTypeMappings makes sure the Go types the schemas and formats are mapped to can be referenced.
+func validateTypeMappings(mappings ...map[string]string) error {
+	for _, mapping := range mappings {
+		for key, goType := range mapping {
+			if _, name := splitGoType(goType); name == "" {
+				return errors.Newf("invalid Go type %s for %s, expected either a builtin type or `import/path.Type`", goType, key)
+			}
+		}
+	}
+	return nil
+}
+
+// splitGoType splits a Go type written as `import/path.Type` into its import path and its name, the import path being
+// empty for builtin types.
+func splitGoType(goType string) (string, string) {
+	idx := strings.LastIndex(goType, ".")
+	if idx < 0 {
+		return "", goType
+	}
+	if idx < strings.LastIndex(goType, "/") {
+		return "", ""
+	}
+	return goType[:idx], goType[idx+1:]
+}
+
+// isOperationSelected reports whether the operation is generated, according to the include and exclude filters.
+func (g *Generator) isOperationSelected(apiPath string, operation *v3.Operation) bool {
+	if !g.flags.Include.IsEmpty() && !g.flags.Include.Matches(apiPath, operation) {
+		return false
+	}
+	return !g.flags.Exclude.Matches(apiPath, operation)
+}
+
+// componentTypeName returns the name of the type generated for a component schema, as assigned by
+// reserveComponentTypeNames.
+func (g *Generator) componentTypeName(name string) string {
+	return g.componentTypes[name]
+}
+
+// reserveComponentTypeNames names the types of the component schemas, which can be overridden. Schema names which are
+// not exported Go identifiers, such as `type` or `pet-store`, are sanitized, and suffixed when they collide with
+// another schema.
+func (g *Generator) reserveComponentTypeNames(schemaProxies *orderedmap.Map[string, *base.SchemaProxy]) error {
+	keys := orderedKeys(g.flags.Ordering, schemaProxies)
+	// overridden names are reserved first, so that they are never the ones being suffixed
+	for _, key := range keys {
+		typeName, exists := g.flags.TypeNames[key]
+		if !exists {
+			continue
+		}
+		if !token.IsIdentifier(typeName) || !token.IsExported(typeName) {
+			return errors.Newf("invalid type name %s for schema %s, it must be an exported Go identifier", typeName, key)
+		}
+		if owner, used := g.typeNames[typeName]; used && owner != schemaProxies.Value(key) {
+			return errors.Newf("type name %s of schema %s is already used by another schema", typeName, key)
+		}
+		g.componentTypes[key] = g.reserveTypeName(typeName, schemaProxies.Value(key))
+	}
+	for _, key := range keys {
+		if _, exists := g.componentTypes[key]; exists {
+			continue
+		}
+		typeName := key
+		if !token.IsIdentifier(typeName) || !token.IsExported(typeName) {
+			if typeName = goIdentifier(key, "Schema"); typeName == "" {
+				typeName = "Schema"
+			}
+		}
+		g.componentTypes[key] = g.reserveTypeName(typeName, schemaProxies.Value(key))
+	}
+	return nil
+}
+
+// mappedType writes the existing Go type a component schema or a format is mapped to into stmt, and reports whether
+// there was one.
+func (g *Generator) mappedType(stmt *jen.Statement, proxy *base.SchemaProxy, schema *base.Schema) bool {
+	goType, exists := "", false
+	if proxy.IsReference() && g.isComponentRef(proxy) {
+		goType, exists = g.flags.TypeMappings[refName(proxy.GetReference())]
+	}
+	if !exists && schema.Format != "" {
+		goType, exists = g.flags.FormatMappings[schema.Format]
+	}
+	if !exists {
+		return false
+	}
+	importPath, name := splitGoType(goType)
+	if importPath == "" {
+		stmt.Id(name)
+	} else {
+		stmt.Qual(importPath, name)
+	}
+	return true
+}
@@ -0,0 +1,299 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// operationParameters merges the parameters declared on the path item with the ones of the operation,
+// the latter overriding the former when they share the same name and location.
+func operationParameters(pathItem *v3.PathItem, operation *v3.Operation) []*v3.Parameter {
+	params := make([]*v3.Parameter, 0, len(pathItem.Parameters)+len(operation.Parameters))
+	for _, pathParam := range pathItem.Parameters {
+		overridden := slices.Contains(
+			slices.Map(operation.Parameters, func(in *v3.Parameter) string { return in.In + ":" + in.Name }),
+			pathParam.In+":"+pathParam.Name,
+		)
+		if !overridden {
+			params = append(params, pathParam)
+		}
+	}
+	return append(params, operation.Parameters...)
+}
+
+// paramsStructCode describes the generated `<Method>Params` struct of an operation.
+type paramsStructCode struct {
+	param jen.Code
+	// exploded is set when the struct has an `explodedQuery` method, encoding the exploded array query parameters.
+	exploded bool
+}
+
+type paramField struct {
+	goName   string
+	param    *v3.Parameter
+	schema   *base.Schema
+	required bool
+	isArray  bool
+}
+
+// format returns the format of the parameter, or of its items for arrays, which tells dates from date-times.
+func (field paramField) format() string {
+	if field.isArray && field.schema.Items != nil && field.schema.Items.IsA() && field.schema.Items.A != nil {
+		if items := field.schema.Items.A.Schema(); items != nil {
+			return items.Format
+		}
+		return ""
+	}
+	return field.schema.Format
+}
+
+// paramArraySeparator returns the separator used to serialize a non exploded array parameter.
+func paramArraySeparator(param *v3.Parameter) string {
+	switch param.Style {
+	case "spaceDelimited":
+		return " "
+	case "pipeDelimited":
+		return "|"
+	default:
+		return ","
+	}
+}
+
+// paramExploded reports whether array values should be sent as repeated parameters.
+func paramExploded(param *v3.Parameter) bool {
+	if param.Explode != nil {
+		return *param.Explode
+	}
+	// form is the default style for query and cookie parameters, and explodes by default
+	return (param.Style == "" || param.Style == "form") && (param.In == "query" || param.In == "cookie")
+}
+
+// generateParamsStruct emits the `<Method>Params` struct holding the query, header and cookie parameters of the
+// operation, along with the code encoding it onto the sdk.Request.
+// It returns nil when the operation has no such parameters.
+func (g *Generator) generateParamsStruct(f *jen.File, methodName string, params []*v3.Parameter) (*paramsStructCode, error) {
+	log := logger.New()
+	fields := make([]paramField, 0)
+	usedNames := make(map[string]bool)
+	for _, param := range params {
+		if !slices.Contains(slices.Of("query", "header", "cookie"), param.In) {
+			continue
+		}
+		if param.Schema == nil {
+			log.Warn("skipping parameter without schema, content based parameters are not supported")
+			continue
+		}
+		schema, err := param.Schema.BuildSchema()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schema for %s parameter %s", param.In, param.Name)
+		}
+		if slices.Contains(schema.Type, "object") {
+			log.Warn("skipping object parameter, only primitives and arrays of primitives are supported")
+			continue
+		}
+		goName := caps.ToCamel(param.Name)
+		if usedNames[goName] {
+			goName += caps.ToCamel(param.In)
+		}
+		usedNames[goName] = true
+		fields = append(fields, paramField{
+			goName:   goName,
+			param:    param,
+			schema:   schema,
+			required: param.Required != nil && *param.Required,
+			isArray:  slices.Contains(schema.Type, "array"),
+		})
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	structName := methodName + "Params"
+	structFields := make([]jen.Code, 0, len(fields))
+	for _, field := range fields {
+		stmt := jen.Id(field.goName)
+		if !field.required && !field.isArray {
+			stmt.Op("*")
+		}
+		if err := g.oas3TypeToGoType(stmt, field.param.Schema, field.schema, methodName+field.goName); err != nil {
+			return nil, errors.Wrapf(err, "invalid type for %s parameter %s", field.param.In, field.param.Name)
+		}
+		structFields = append(structFields, jen.Commentf("%s is the `%s` %s parameter.", field.goName, field.param.Name, field.param.In))
+		for _, line := range strings.Split(strings.TrimSpace(field.param.Description), "\n") {
+			if line != "" {
+				structFields = append(structFields, jen.Comment(line))
+			}
+		}
+		if def := paramDefault(field); def != "" {
+			structFields = append(structFields, jen.Commentf("Defaults to `%s` when unset.", def))
+		}
+		structFields = append(structFields, stmt)
+	}
+
+	f.Commentf("%s holds the query, header and cookie parameters of the %s operation.", structName, methodName)
+	f.Type().Id(structName).Struct(structFields...)
+
+	f.Comment("requestOptions encodes the parameters onto the request.")
+	f.Func().Params(jen.Id("p").Op("*").Id(structName)).Id("requestOptions").Params().
+		Index().Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Request")).
+		BlockFunc(func(group *jen.Group) {
+			group.If(jen.Id("p").Op("==").Nil()).Block(
+				jen.Id("p").Op("=").Op("&").Id(structName).Values(),
+			)
+			group.Var().Id("opts").Index().Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Request"))
+			hasCookies := slices.Contains(slices.Map(fields, func(in paramField) string { return in.param.In }), "cookie")
+			if hasCookies {
+				group.Var().Id("cookies").Index().String()
+			}
+			for _, field := range fields {
+				generateParamEncoding(group, field)
+			}
+			if hasCookies {
+				group.If(jen.Len(jen.Id("cookies")).Op(">").Lit(0)).Block(
+					jen.Id("opts").Op("=").Append(jen.Id("opts"), jen.Qual(sdkPackage, "WithHeader").Call(
+						jen.Lit("Cookie"),
+						jen.Qual("strings", "Join").Call(jen.Id("cookies"), jen.Lit("; ")),
+					)),
+				)
+			}
+			group.Return(jen.Id("opts"))
+		})
+
+	exploded := slices.Filter(fields, isExplodedQuery)
+	if len(exploded) > 0 {
+		f.Comment("explodedQuery encodes the exploded array query parameters, which are sent as one pair per value.")
+		f.Func().Params(jen.Id("p").Op("*").Id(structName)).Id("explodedQuery").Params().Qual("net/url", "Values").
+			BlockFunc(func(group *jen.Group) {
+				group.Id("query").Op(":=").Make(jen.Qual("net/url", "Values"))
+				group.If(jen.Id("p").Op("==").Nil()).Block(jen.Return(jen.Id("query")))
+				for _, field := range exploded {
+					value := jen.Id("p").Dot(field.goName)
+					group.If(jen.Len(value).Op(">").Lit(0)).Block(
+						jen.Id("query").Index(jen.Lit(field.param.Name)).Op("=").Id("formatParams").Call(value.Clone(), jen.Lit(field.format())),
+					)
+				}
+				group.Return(jen.Id("query"))
+			})
+	}
+
+	return &paramsStructCode{
+		param:    jen.Id("params").Op("*").Id(structName),
+		exploded: len(exploded) > 0,
+	}, nil
+}
+
+// isExplodedQuery reports whether the parameter is an array query parameter sent as one pair per value, which the
+// sdk cannot encode as it joins the values of a query parameter with commas.
+func isExplodedQuery(field paramField) bool {
+	return field.isArray && field.param.In == "query" && paramExploded(field.param)
+}
+
+// paramDefault returns the literal default value of a scalar parameter, if any.
+func paramDefault(field paramField) string {
+	if field.required || field.isArray || field.schema.Default == nil {
+		return ""
+	}
+	return field.schema.Default.Value
+}
+
+// generateParamEncoding emits the code appending the request option of a single parameter.
+func generateParamEncoding(group *jen.Group, field paramField) {
+	name := field.param.Name
+	value := jen.Id("p").Dot(field.goName)
+	encode := func(formatted jen.Code) jen.Code {
+		switch field.param.In {
+		case "header":
+			return jen.Id("opts").Op("=").Append(jen.Id("opts"), jen.Qual(sdkPackage, "WithHeader").Call(jen.Lit(name), formatted))
+		case "cookie":
+			return jen.Id("cookies").Op("=").Append(jen.Id("cookies"),
+				jen.Parens(jen.Op("&").Qual("net/http", "Cookie").Values(jen.Dict{
+					jen.Id("Name"):  jen.Lit(name),
+					jen.Id("Value"): formatted,
+				})).Dot("String").Call(),
+			)
+		default:
+			return jen.Id("opts").Op("=").Append(jen.Id("opts"), jen.Qual(sdkPackage, "WithQueryParam").Call(jen.Lit(name), formatted))
+		}
+	}
+
+	switch {
+	case isExplodedQuery(field):
+		// encoded by explodedQuery
+	case field.isArray:
+		group.If(jen.Len(value.Clone()).Op(">").Lit(0)).Block(encode(jen.Qual("strings", "Join").Call(
+			jen.Id("formatParams").Call(value, jen.Lit(field.format())), jen.Lit(paramArraySeparator(field.param)),
+		)))
+	case field.required:
+		group.Add(encode(jen.Id("formatParam").Call(value, jen.Lit(field.format()))))
+	default:
+		ifStmt := group.If(value.Clone().Op("!=").Nil()).Block(
+			encode(jen.Id("formatParam").Call(jen.Op("*").Add(value.Clone()), jen.Lit(field.format()))),
+		)
+		if def := paramDefault(field); def != "" {
+			ifStmt.Else().Block(encode(jen.Lit(def)))
+		}
+	}
+}
+
+// generateParamsHelpers emits the helpers shared by the generated `requestOptions` methods.
+func (g *Generator) generateParamsHelpers() {
+	f := g.generatePackageFile("", g.flags.PackageName, "params")
+	f.Comment("formatParam renders a parameter value the way it is expected on the wire, times being rendered as dates")
+	f.Comment("or date-times according to the format of the parameter.")
+	f.Func().Id("formatParam").Params(jen.Id("value").Any(), jen.Id("format").String()).String().BlockFunc(func(group *jen.Group) {
+		group.Switch(jen.Id("v").Op(":=").Id("value").Assert(jen.Type())).BlockFunc(func(group *jen.Group) {
+			group.Case(jen.String()).Block(jen.Return(jen.Id("v")))
+			group.Case(jen.Qual("time", "Time")).Block(
+				jen.If(jen.Id("format").Op("==").Lit("date")).Block(
+					jen.Return(jen.Id("v").Dot("Format").Call(jen.Qual("time", "DateOnly"))),
+				),
+				jen.Return(jen.Id("v").Dot("Format").Call(jen.Qual("time", "RFC3339"))),
+			)
+			group.Case(jen.Qual("fmt", "Stringer")).Block(jen.Return(jen.Id("v").Dot("String").Call()))
+			group.Default().Block(jen.Return(jen.Qual("fmt", "Sprint").Call(jen.Id("v"))))
+		})
+	})
+
+	f.Comment("formatParams renders each of the given values using formatParam.")
+	f.Func().Id("formatParams").Types(jen.Id("T").Any()).Params(jen.Id("values").Index().Id("T"), jen.Id("format").String()).Index().String().
+		BlockFunc(func(group *jen.Group) {
+			group.Id("formatted").Op(":=").Make(jen.Index().String(), jen.Len(jen.Id("values")))
+			group.For(jen.List(jen.Id("i"), jen.Id("value")).Op(":=").Range().Id("values")).Block(
+				jen.Id("formatted").Index(jen.Id("i")).Op("=").Id("formatParam").Call(jen.Id("value"), jen.Id("format")),
+			)
+			group.Return(jen.Id("formatted"))
+		})
+
+	contextParam := jen.Id("ctx").Qual("context", "Context")
+	f.Type().Id("explodedQueryKey").Struct()
+	f.Comment("withExplodedQuery attaches the exploded query parameters of an operation to the context of its request.")
+	f.Func().Id("withExplodedQuery").Params(contextParam.Clone(), jen.Id("query").Qual("net/url", "Values")).
+		Qual("context", "Context").Block(
+		jen.Return(jen.Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id("explodedQueryKey").Values(), jen.Id("query"))),
+	)
+	f.Comment("encodeExplodedQuery appends the exploded query parameters attached to the context to the URL of the request,")
+	f.Comment("as the sdk would join their values with commas.")
+	f.Func().Id("encodeExplodedQuery").
+		Params(contextParam.Clone(), jen.Id("req").Op("*").Qual("net/http", "Request")).Error().
+		Block(
+			jen.List(jen.Id("query"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("explodedQueryKey").Values()).
+				Assert(jen.Qual("net/url", "Values")),
+			jen.If(jen.Len(jen.Id("query")).Op("==").Lit(0)).Block(jen.Return(jen.Nil())),
+			jen.Id("values").Op(":=").Id("req").Dot("URL").Dot("Query").Call(),
+			jen.For(jen.List(jen.Id("name"), jen.Id("items")).Op(":=").Range().Id("query")).Block(
+				jen.Id("values").Index(jen.Id("name")).Op("=").Append(
+					jen.Id("values").Index(jen.Id("name")), jen.Id("items").Op("..."),
+				),
+			),
+			jen.Id("req").Dot("URL").Dot("RawQuery").Op("=").Id("values").Dot("Encode").Call(),
+			jen.Return(jen.Nil()),
+		)
+}
@@ -0,0 +1,15 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestParamsGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "params", spec: "params"},
+	})
+}
+
+func TestParamsEncoding(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "params", spec: "params"}, "params")
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cavaliergopher/grab/v3"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/utils"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// remoteTimeout bounds the time spent fetching a remote spec file or reference.
+const remoteTimeout = 30 * time.Second
+
+// remoteClient fetches the remote spec files and references.
+var remoteClient = &http.Client{Timeout: remoteTimeout}
+
+// componentSchemaPrefix is the prefix of the references to the schemas of the `components` section.
+const componentSchemaPrefix = "#/components/schemas/"
+
+// refName returns the name of the schema a reference points to, which is the last segment of its fragment, or the
+// name of the referenced file when it has none.
+func refName(ref string) string {
+	file, fragment, _ := strings.Cut(ref, "#")
+	if strings.Trim(fragment, "/") != "" {
+		return path.Base(fragment)
+	}
+	return strings.TrimSuffix(path.Base(file), path.Ext(file))
+}
+
+// refKey identifies the schema a reference points to across all the files of the document, since relative
+// references are resolved against the file declaring them.
+func refKey(proxy *base.SchemaProxy) string {
+	ref := proxy.GetReference()
+	origin := proxy.GetReferenceOrigin()
+	if origin == nil || origin.AbsoluteLocation == "" {
+		return ref
+	}
+	_, fragment, _ := strings.Cut(ref, "#")
+	return origin.AbsoluteLocation + "#" + fragment
+}
+
+// isComponentRef reports whether the reference points to a schema of the `components` section of the document,
+// which are all generated up front. Other references, such as the ones pointing to `$defs` or to other files, are
+// generated when first encountered.
+func (g *Generator) isComponentRef(proxy *base.SchemaProxy) bool {
+	name, found := strings.CutPrefix(proxy.GetReference(), componentSchemaPrefix)
+	if !found || strings.Contains(name, "/") {
+		return false
+	}
+	// the components of other files are not generated up front
+	origin := proxy.GetReferenceOrigin()
+	return origin == nil || origin.Index == nil || origin.Index == g.model.Index
+}
+
+// referencedTypeName returns the name of the type generated for a reference, generating it into the `dtos` package
+// the first time it is encountered.
+func (g *Generator) referencedTypeName(proxy *base.SchemaProxy) (string, error) {
+	ref := proxy.GetReference()
+	if g.isComponentRef(proxy) {
+		return g.componentTypeName(refName(ref)), nil
+	}
+	key := refKey(proxy)
+	if typeName, exists := g.refTypes[key]; exists {
+		return typeName, nil
+	}
+	name := goIdentifier(refName(ref), "Schema")
+	if name == "" {
+		name = "Schema"
+	}
+	typeName := g.reserveTypeName(name, proxy)
+	g.refTypes[key] = typeName
+	if err := g.generateSchema(g.dtosFile(), typeName, proxy); err != nil {
+		return "", errors.Wrapf(err, "invalid referenced schema %s", ref)
+	}
+	return typeName, nil
+}
+
+// documentConfiguration returns the configuration resolving the references of the spec at specPath against its
+// directory. Remote references are only followed for the allowed hosts, and fetched within ctx.
+func documentConfiguration(ctx context.Context, specPath string, allowedHosts []string) *datamodel.DocumentConfiguration {
+	return &datamodel.DocumentConfiguration{
+		AllowFileReferences:   true,
+		AllowRemoteReferences: true,
+		BasePath:              path.Dir(specPath),
+		SpecFilePath:          path.Base(specPath),
+		RemoteURLHandler:      allowedHostsHandler(ctx, allowedHosts),
+	}
+}
+
+// downloadSpec downloads the spec at uri into dir, along with the files of the same server it references, so that its
+// relative references are resolved the same way as the ones of a local spec. Files are laid out in dir following their
+// URL path, and the path of the spec is returned.
+func downloadSpec(ctx context.Context, dir string, uri url.URL) (string, error) {
+	return downloadSpecFile(ctx, dir, uri, make(map[string]string))
+}
+
+func downloadSpecFile(ctx context.Context, dir string, uri url.URL, downloaded map[string]string) (string, error) {
+	log := logger.FromContext(ctx)
+	uri.Fragment = ""
+	if filename, exists := downloaded[uri.String()]; exists {
+		return filename, nil
+	}
+	urlPath := path.Clean("/" + uri.Path)
+	if urlPath == "/" {
+		urlPath = "/openapi"
+	}
+	filename := filepath.Join(dir, filepath.FromSlash(urlPath))
+	downloaded[uri.String()] = filename
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", errors.Wrapf(err, "failed to create directory %s", filepath.Dir(filename))
+	}
+	request, err := grab.NewRequest(filename, uri.String())
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid spec url %s", uri.String())
+	}
+	downloader := grab.NewClient()
+	downloader.HTTPClient = remoteClient
+	response := downloader.Do(request.WithContext(ctx))
+	if err := response.Err(); err != nil {
+		return "", errors.Wrapf(err, "failed to download %s", uri.String())
+	}
+	log.Debug("file downloaded", zap.String("url", uri.String()), zap.Int64("size", response.Size()))
+
+	refs, err := fileRefs(filename)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		target := uri.ResolveReference(ref)
+		// references to other servers are fetched by libopenapi, provided their host is allowed
+		if target.Scheme != uri.Scheme || target.Host != uri.Host {
+			continue
+		}
+		if _, err := downloadSpecFile(ctx, dir, *target, downloaded); err != nil {
+			return "", err
+		}
+	}
+	return filename, nil
+}
+
+// fileRefs returns the references of a spec file pointing to other files.
+func fileRefs(filename string) ([]*url.URL, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	var (
+		refs    []*url.URL
+		collect func(node *yaml.Node) error
+	)
+	collect = func(node *yaml.Node) error {
+		if node.Kind == yaml.MappingNode {
+			for idx := 0; idx+1 < len(node.Content); idx += 2 {
+				key, value := node.Content[idx], node.Content[idx+1]
+				if key.Value != "$ref" || value.Kind != yaml.ScalarNode || strings.HasPrefix(value.Value, "#") {
+					continue
+				}
+				ref, err := url.Parse(value.Value)
+				if err != nil {
+					return errors.Wrapf(err, "invalid reference %s in %s", value.Value, filename)
+				}
+				refs = append(refs, ref)
+			}
+		}
+		for _, child := range node.Content {
+			if err := collect(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(&document); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// allowedHostsHandler returns a handler fetching remote references within ctx, refusing the ones whose host is not
+// allowed.
+func allowedHostsHandler(ctx context.Context, allowedHosts []string) utils.RemoteURLHandler {
+	return func(rawURL string) (*http.Response, error) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid remote reference %s", rawURL)
+		}
+		if !slices.Contains(allowedHosts, u.Hostname()) && !slices.Contains(allowedHosts, u.Host) {
+			return nil, errors.Newf("remote reference %s is not allowed, its host %s must be explicitly allowed", rawURL, u.Hostname())
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid remote reference %s", rawURL)
+		}
+		return remoteClient.Do(request)
+	}
+}
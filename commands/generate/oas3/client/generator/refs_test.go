@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferencesGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "multifile", spec: "multifile/api"},
+	})
+}
+
+func TestRemoteReferencesAllowedHosts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("Pet:\n  type: object\n  properties:\n    name: {type: string}\n"))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	spec := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(fmt.Sprintf(`openapi: 3.0.3
+info: {title: remote, version: "1"}
+paths: {}
+components:
+  schemas:
+    Pet: {$ref: "%s/schemas.yaml#/Pet"}
+`, server.URL)), 0644))
+
+	testCases := []struct {
+		name         string
+		allowedHosts []string
+		allowed      bool
+	}{
+		{name: "no allowed host"},
+		{name: "other host", allowedHosts: []string{"example.com"}},
+		{name: "other port", allowedHosts: []string{serverURL.Hostname() + ":1"}},
+		{name: "allowed hostname", allowedHosts: []string{serverURL.Hostname()}, allowed: true},
+		{name: "allowed host and port", allowedHosts: []string{serverURL.Host}, allowed: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			g, err := GeneratorFromFile(context.Background(), spec, tc.allowedHosts)
+			if !tc.allowed {
+				// libopenapi reports the reference as missing, the refused host is never contacted
+				require.ErrorContains(t, err, "cannot resolve reference")
+				require.Zero(t, requests.Load())
+				return
+			}
+			require.NoError(t, err)
+			require.NotZero(t, requests.Load())
+			schema := g.model.Model.Components.Schemas.Value("Pet").Schema()
+			require.NotNil(t, schema)
+			require.Equal(t, []string{"object"}, schema.Type)
+		})
+	}
+}
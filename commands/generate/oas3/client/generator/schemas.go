@@ -1,26 +1,30 @@
 package generator
 
 import (
+	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/chanced/caps"
 	"github.com/dave/jennifer/jen"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 
 	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
 	"github.com/kiwiworks/rodent/slices"
 )
 
-func (g *Generator) generateFile(packageName, name string) *jen.File {
-	f := jen.NewFilePathName(packageName, name)
-	if !strings.HasPrefix(name, ".go") {
-		name += ".go"
+func (g *Generator) generatePackageFile(packageDir, packageName, filename string) *jen.File {
+	f := jen.NewFilePathName(path.Join(g.moduleName, packageDir), packageName)
+	if !strings.HasSuffix(filename, ".go") {
+		filename += ".go"
 	}
-	g.files[path.Join(packageName, name)] = f
-	f.HeaderComment("Code generated by github.com/kiwiworks/rodent-cli")
-	f.HeaderComment("DO NOT EDIT.")
+	g.files[path.Join(packageDir, filename)] = f
+	f.HeaderComment(generatedHeader + " DO NOT EDIT.")
 
 	return f
 }
@@ -69,38 +73,193 @@ func oas3NumberFormatToGoType(stmt *jen.Statement, format string) error {
 	return nil
 }
 
-func oas3ObjectToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, _ *base.Schema) error {
-	stmt.Id(path.Base(proxy.GetReference()))
+func (g *Generator) dtoPackage() string {
+	return path.Join(g.moduleName, g.flags.DTOsPackageName)
+}
+
+func (g *Generator) oas3ObjectToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, schema *base.Schema, name string) error {
+	if g.isDictionarySchema(schema) {
+		stmt.Map(jen.String())
+		return g.additionalPropertiesType(stmt, schema, name)
+	}
+	if !proxy.IsReference() {
+		// inline objects without any name hint have no name we could refer to
+		stmt.Map(jen.String()).Any()
+		return nil
+	}
+	typeName, err := g.referencedTypeName(proxy)
+	if err != nil {
+		return err
+	}
+	stmt.Qual(g.dtoPackage(), typeName)
 	return nil
 }
 
-func oas3TypeToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, schema *base.Schema) error {
-	if schema.Type == nil {
-		stmt.Any()
+func (g *Generator) schemaProxyToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, name string) error {
+	schema, err := proxy.BuildSchema()
+	if err != nil {
+		return errors.Wrapf(err, "invalid schema %s", proxy.GetReference())
+	}
+	return g.oas3TypeToGoType(stmt, proxy, schema, name)
+}
+
+// reserveTypeName registers a type name of the `dtos` package for the given schema, suffixing it when it is already
+// used by another one.
+func (g *Generator) reserveTypeName(name string, proxy *base.SchemaProxy) string {
+	candidate := name
+	for idx := 2; ; idx++ {
+		owner, used := g.typeNames[candidate]
+		if !used || owner == proxy {
+			g.typeNames[candidate] = proxy
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, idx)
+	}
+}
+
+// inlineSchemaKey identifies an inline schema by the component schema declaring it and its JSON pointer within that
+// schema, since flattening an `allOf` member reaches the same inline schemas through other proxies. Inline schemas
+// declared outside of the component schemas are identified by their proxy.
+type inlineSchemaKey struct {
+	component string
+	pointer   string
+	proxy     *base.SchemaProxy
+}
+
+// jsonPointerEscaper escapes a key into a JSON pointer token.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// locateComponentSchemas indexes the nodes of the component schemas by the component declaring them and their JSON
+// pointer within it.
+func (g *Generator) locateComponentSchemas(schemaProxies *orderedmap.Map[string, *base.SchemaProxy]) {
+	for pair := schemaProxies.First(); pair != nil; pair = pair.Next() {
+		g.locateSchemaNodes(pair.Value().GetValueNode(), pair.Key(), "")
+	}
+}
+
+func (g *Generator) locateSchemaNodes(node *yaml.Node, component, pointer string) {
+	if node == nil {
+		return
+	}
+	if _, located := g.schemaLocations[node]; located {
+		return
+	}
+	g.schemaLocations[node] = inlineSchemaKey{component: component, pointer: pointer}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			g.locateSchemaNodes(node.Content[idx+1], component, pointer+"/"+jsonPointerEscaper.Replace(node.Content[idx].Value))
+		}
+	case yaml.SequenceNode:
+		for idx, item := range node.Content {
+			g.locateSchemaNodes(item, component, pointer+"/"+strconv.Itoa(idx))
+		}
+	}
+}
+
+// inlineSchemaKey returns the key identifying the inline schema.
+func (g *Generator) inlineSchemaKey(proxy *base.SchemaProxy) inlineSchemaKey {
+	if key, located := g.schemaLocations[proxy.GetValueNode()]; located {
+		return key
+	}
+	return inlineSchemaKey{proxy: proxy}
+}
+
+// inlineTypeName generates the named type of an inline schema into the `dtos` package the first time it is
+// encountered, and returns its name.
+func (g *Generator) inlineTypeName(proxy *base.SchemaProxy, name string) (string, error) {
+	key := g.inlineSchemaKey(proxy)
+	if typeName, exists := g.inlineTypes[key]; exists {
+		return typeName, nil
+	}
+	typeName := g.reserveTypeName(name, proxy)
+	if schema := g.componentSchemaNamed(name); typeName != name && schema != "" {
+		logger.New().Warn("inline type collides with a component schema, it is suffixed",
+			zap.String("type", name), zap.String("renamed", typeName), zap.String("schema", schema))
+	}
+	g.inlineTypes[key] = typeName
+	if err := g.generateSchema(g.dtosFile(), typeName, proxy); err != nil {
+		return "", errors.Wrapf(err, "invalid inline schema %s", typeName)
+	}
+	return typeName, nil
+}
+
+// componentSchemaNamed returns the name of the component schema generated as the given type, if any.
+func (g *Generator) componentSchemaNamed(typeName string) string {
+	for schema, name := range g.componentTypes {
+		if name == typeName {
+			return schema
+		}
+	}
+	return ""
+}
+
+func (g *Generator) dtosFile() *jen.File {
+	if f, exists := g.files[path.Join(g.flags.DTOsPackageName, "dtos.go")]; exists {
+		return f
+	}
+	return g.generatePackageFile(g.flags.DTOsPackageName, g.flags.DTOsPackageName, "dtos")
+}
+
+// oas3TypeToGoType writes the Go type of the schema into stmt. Inline schemas requiring a named type (objects, enums
+// and unions) are generated into the `dtos` package under the given name, and fall back to untyped values when the
+// name is empty.
+func (g *Generator) oas3TypeToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, schema *base.Schema, name string) error {
+	if g.mappedType(stmt, proxy, schema) {
 		return nil
 	}
-	kind := schema.Type[0]
+	if proxy.IsReference() && g.isNamedSchema(schema) {
+		typeName, err := g.referencedTypeName(proxy)
+		if err != nil {
+			return err
+		}
+		stmt.Qual(g.dtoPackage(), typeName)
+		return nil
+	}
+	if !proxy.IsReference() && g.isNamedSchema(schema) && name != "" {
+		typeName, err := g.inlineTypeName(proxy, name)
+		if err != nil {
+			return err
+		}
+		stmt.Qual(g.dtoPackage(), typeName)
+		return nil
+	}
+	kind := g.schemaType(schema)
 	switch kind {
 	case "string":
 		return oas3StringFormatToGoType(stmt, schema.Format)
-	case "number", "integer":
+	case "integer":
+		if schema.Format == "" {
+			stmt.Int64()
+			return nil
+		}
+		return oas3NumberFormatToGoType(stmt, schema.Format)
+	case "number":
 		return oas3NumberFormatToGoType(stmt, schema.Format)
 	case "boolean":
 		stmt.Bool()
 	case "object":
-		return oas3ObjectToGoType(stmt, proxy, schema)
+		return g.oas3ObjectToGoType(stmt, proxy, schema, name)
 	case "array":
 		stmt.Index()
-		if schema.Items != nil && schema.Items.IsA() {
-			proxy = schema.Items.A
-			itemSchema, err := proxy.BuildSchema()
-			if err != nil {
-				return errors.Wrapf(err, "invalid array item schema %s", proxy.GetReference())
-			}
-			schema = itemSchema
-		}
-		return oas3TypeToGoType(stmt, proxy, schema)
+		if schema.Items == nil || !schema.Items.IsA() || g.isTupleSchema(schema) {
+			stmt.Any()
+			return nil
+		}
+		proxy = schema.Items.A
+		itemSchema, err := proxy.BuildSchema()
+		if err != nil {
+			return errors.Wrapf(err, "invalid array item schema %s", proxy.GetReference())
+		}
+		if name != "" {
+			name += "Item"
+		}
+		return g.oas3TypeToGoType(stmt, proxy, itemSchema, name)
 	case "":
+		if g.isDictionarySchema(schema) {
+			return g.oas3ObjectToGoType(stmt, proxy, schema, name)
+		}
+		// untyped schemas, or schemas allowing several types
 		stmt.Any()
 	default:
 		return errors.Newf("unsupported type %s", kind)
@@ -109,40 +268,117 @@ func oas3TypeToGoType(stmt *jen.Statement, proxy *base.SchemaProxy, schema *base
 }
 
 func (g *Generator) generateSchemas(schemaProxies *orderedmap.Map[string, *base.SchemaProxy]) error {
-	f := g.generateFile("dtos", "dtos")
-	for key := range schemaProxies.KeysFromNewest() {
-		schemaProxy := schemaProxies.Value(key)
-		schema, err := schemaProxy.BuildSchema()
+	f := g.dtosFile()
+	if err := g.reserveComponentTypeNames(schemaProxies); err != nil {
+		return err
+	}
+	g.locateComponentSchemas(schemaProxies)
+	for _, key := range orderedKeys(g.flags.Ordering, schemaProxies) {
+		if _, mapped := g.flags.TypeMappings[key]; mapped {
+			continue
+		}
+		if err := g.generateSchema(f, g.componentTypeName(key), schemaProxies.Value(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNamedSchema reports whether a referenced schema is generated as its own named type in the `dtos` package,
+// references to other schemas are inlined as their underlying Go type.
+func (g *Generator) isNamedSchema(schema *base.Schema) bool {
+	if g.isDictionarySchema(schema) {
+		return false
+	}
+	return g.isEnumSchema(schema) || g.isTupleSchema(schema) || g.isConditionalObject(schema) ||
+		len(schema.AllOf) > 0 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 ||
+		slices.Contains(schema.Type, "object") || schema.Properties != nil && schema.Properties.Len() > 0
+}
+
+func (g *Generator) generateSchema(f *jen.File, name string, proxy *base.SchemaProxy) error {
+	schema, err := proxy.BuildSchema()
+	if err != nil {
+		return errors.Wrapf(err, "invalid schema %s", name)
+	}
+	switch {
+	case g.isEnumSchema(schema):
+		return g.generateEnum(f, name, schema)
+	case g.isTupleSchema(schema):
+		return g.generateTuple(f, name, schema)
+	case len(schema.OneOf) > 0:
+		return g.generateUnion(f, name, schema, schema.OneOf, true)
+	case len(schema.AnyOf) > 0:
+		return g.generateUnion(f, name, schema, schema.AnyOf, false)
+	case g.isNamedSchema(schema):
+		return g.generateStruct(f, name, schema)
+	default:
+		stmt := jen.Type().Id(name).Op("=")
+		if err := g.oas3TypeToGoType(stmt, proxy, schema, name); err != nil {
+			return errors.Wrapf(err, "invalid schema type %s", name)
+		}
+		f.Add(stmt)
+		return nil
+	}
+}
+
+// formTag returns the `url` tag of a property encoded into a form, omitting it whenever its json counterpart would be.
+func formTag(name string, jsonOptions []string) string {
+	if len(jsonOptions) > 0 {
+		return name + ",omitempty"
+	}
+	return name
+}
+
+func (g *Generator) generateStruct(f *jen.File, name string, schema *base.Schema) error {
+	properties, err := g.collectProperties(schema)
+	if err != nil {
+		return errors.Wrapf(err, "invalid schema %s", name)
+	}
+	fields := make([]jen.Code, 0)
+	form := g.isFormSchema(schema)
+	for _, property := range properties {
+		propSchema, err := property.proxy.BuildSchema()
 		if err != nil {
-			return errors.Wrapf(err, "invalid schema %s", key)
-		}
-		fields := make([]jen.Code, 0)
-		for prop := range schema.Properties.KeysFromNewest() {
-			if prop == "$schema" {
-				continue
-			}
-			propSchemaProxy := schema.Properties.Value(prop)
-			propSchema, err := propSchemaProxy.BuildSchema()
-			if err != nil {
-				return errors.Wrapf(err, "invalid property schema %s.%s", key, prop)
-			}
-			goPropName := caps.ToCamel(prop)
-			stmt := jen.Id(goPropName)
-			if err = oas3TypeToGoType(stmt, propSchemaProxy, propSchema); err != nil {
-				return errors.Wrapf(err, "invalid property type %s.%s (%s)", key, prop, propSchema.Type)
-			}
-			jsonProps := slices.Of(prop)
-			if !slices.Contains(schema.Required, prop) {
-				jsonProps = append(jsonProps, "omitempty")
-			}
-			stmt.Tag(map[string]string{"json": strings.Join(jsonProps, ",")})
-			//fmt.Printf("%s.%s -> %s(%s)", key, prop, propSchema.Type, propSchema.Format)
-			fields = append(fields, stmt)
-		}
-		f.Type().Id(key).Struct(fields...)
+			return errors.Wrapf(err, "invalid property schema %s.%s", name, property.name)
+		}
+		goPropName := caps.ToCamel(property.name)
+		stmt := jen.Id(goPropName)
+		// inherited properties name their inline types after the schema declaring them, which generates them too
+		owner := name
+		if property.owner != "" {
+			owner = property.owner
+		}
+		tagOptions, err := g.propertyType(stmt, property, propSchema, owner+caps.ToCamel(property.name))
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "invalid property type %s.%s (%s)", name, property.name, propSchema.Type)
+		}
+		jsonProps := append(slices.Of(property.name), tagOptions...)
+		tags := map[string]string{"json": strings.Join(jsonProps, ",")}
+		if form {
+			tags["url"] = formTag(property.name, tagOptions)
 		}
+		stmt.Tag(tags)
+		fields = append(fields, stmt)
+	}
+	if unevaluated := schema.UnevaluatedProperties; g.jsonSchema2020() && unevaluated != nil && unevaluated.IsB() && !unevaluated.B {
+		logger.New().Warn("unevaluatedProperties is not enforced, unknown properties are ignored when decoding",
+			zap.String("type", name))
+	}
+	_, hasAdditionalProperties := g.additionalProperties(schema)
+	if hasAdditionalProperties {
+		stmt := jen.Id("AdditionalProperties").Map(jen.String())
+		if err := g.additionalPropertiesType(stmt, schema, name); err != nil {
+			return errors.Wrapf(err, "invalid schema %s", name)
+		}
+		tags := map[string]string{"json": "-"}
+		if form {
+			tags["url"] = "-"
+		}
+		fields = append(fields, stmt.Tag(tags))
+	}
+	f.Type().Id(name).Struct(fields...)
+	if hasAdditionalProperties {
+		return g.generateAdditionalPropertiesMarshalling(f, name, schema, properties)
 	}
 	return nil
 }
@@ -0,0 +1,11 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestInlineSchemasGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "inline", spec: "inline"},
+	})
+}
@@ -0,0 +1,435 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+const (
+	securityKindAPIKey = "APIKey"
+	securityKindBearer = "BearerToken"
+	securityKindBasic  = "BasicAuth"
+	securityKindOAuth2 = "OAuth2"
+)
+
+// securityScheme is a security scheme of the document the generated client can authenticate with.
+type securityScheme struct {
+	name   string
+	kind   string
+	scheme *v3.SecurityScheme
+}
+
+// securitySchemes lists the security schemes of the document supported by the generated client.
+func securitySchemes(document v3.Document, ordering string) []securityScheme {
+	if document.Components == nil || document.Components.SecuritySchemes == nil {
+		return nil
+	}
+	log := logger.New()
+	schemes := make([]securityScheme, 0)
+	for _, name := range orderedKeys(ordering, document.Components.SecuritySchemes) {
+		scheme := document.Components.SecuritySchemes.Value(name)
+		kind := ""
+		switch {
+		case scheme.Type == "apiKey":
+			kind = securityKindAPIKey
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+			kind = securityKindBearer
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			kind = securityKindBasic
+		case scheme.Type == "oauth2":
+			kind = securityKindOAuth2
+		default:
+			log.Warn("skipping unsupported security scheme",
+				zap.String("scheme", name), zap.String("type", scheme.Type), zap.String("http.scheme", scheme.Scheme))
+			continue
+		}
+		schemes = append(schemes, securityScheme{name: name, kind: kind, scheme: scheme})
+	}
+	return schemes
+}
+
+// optionName names an option of the scheme, after the scheme itself when several schemes share the same kind.
+func (s securityScheme) optionName(schemes []securityScheme, suffix string) string {
+	sameKind := slices.Filter(schemes, func(in securityScheme) bool { return in.kind == s.kind })
+	// a scheme named after its kind, such as `apiKey`, keeps the plain option name
+	if prefix := caps.ToCamel(s.name); len(sameKind) > 1 && !strings.EqualFold(prefix, s.kind) {
+		return "With" + prefix + s.kind + suffix
+	}
+	return "With" + s.kind + suffix
+}
+
+// operationSecuritySchemes returns the names of the schemes the operation accepts, from its own requirements or the
+// ones of the document. An operation accepting several alternatives gets the credentials of all of them.
+func operationSecuritySchemes(document v3.Document, operation *v3.Operation) []string {
+	requirements := document.Security
+	if operation.Security != nil {
+		requirements = operation.Security
+	}
+	names := make([]string, 0)
+	for _, requirement := range requirements {
+		if requirement.Requirements == nil {
+			continue
+		}
+		for name := range requirement.Requirements.KeysFromOldest() {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// generateSecurity emits the options authenticating the requests for each of the supported security schemes of the
+// document, applied only to the operations requiring them. It reports whether any was generated.
+func (g *Generator) generateSecurity(document v3.Document) bool {
+	schemes := securitySchemes(document, g.flags.Ordering)
+	if len(schemes) == 0 {
+		return false
+	}
+	f := g.generatePackageFile("", g.flags.PackageName, "security")
+	configOption := jen.Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config"))
+	contextParam := jen.Id("ctx").Qual("context", "Context")
+	requestParam := jen.Id("req").Op("*").Qual("net/http", "Request")
+
+	f.Comment("TokenSource provides the credentials of a security scheme, it is called for each request requiring them.")
+	f.Type().Id("TokenSource").Interface(
+		jen.Id("Token").Params(contextParam.Clone()).Parens(jen.List(jen.String(), jen.Error())),
+	)
+	f.Comment("TokenSourceFunc adapts a function into a TokenSource.")
+	f.Type().Id("TokenSourceFunc").Func().Params(contextParam.Clone()).Parens(jen.List(jen.String(), jen.Error()))
+	f.Func().Params(jen.Id("f").Id("TokenSourceFunc")).Id("Token").Params(contextParam.Clone()).Parens(jen.List(jen.String(), jen.Error())).Block(
+		jen.Return(jen.Id("f").Call(jen.Id("ctx"))),
+	)
+	f.Comment("StaticToken returns a TokenSource always providing the same credentials.")
+	f.Func().Id("StaticToken").Params(jen.Id("token").String()).Id("TokenSource").Block(
+		jen.Return(jen.Id("TokenSourceFunc").Call(
+			jen.Func().Params(jen.Qual("context", "Context")).Parens(jen.List(jen.String(), jen.Error())).Block(
+				jen.Return(jen.Id("token"), jen.Nil()),
+			),
+		)),
+	)
+
+	f.Type().Id("securityKey").Struct()
+	f.Comment("withSecurity attaches the security schemes accepted by an operation to the context of its request.")
+	f.Func().Id("withSecurity").Params(contextParam.Clone(), jen.Id("schemes").Op("...").String()).Qual("context", "Context").Block(
+		jen.Return(jen.Qual("context", "WithValue").Call(jen.Id("ctx"), jen.Id("securityKey").Values(), jen.Id("schemes"))),
+	)
+	f.Comment("securityInterceptor returns an option applying the credentials of a scheme to the requests accepting it,")
+	f.Comment("requests not issued by the generated operations accept all of them.")
+	f.Func().Id("securityInterceptor").
+		Params(
+			jen.Id("scheme").String(),
+			jen.Id("apply").Func().Params(contextParam.Clone(), requestParam.Clone()).Error(),
+		).
+		Add(configOption.Clone()).
+		Block(
+			jen.Return(jen.Qual(sdkPackage, "AddRequestInterceptor").Call(
+				jen.Func().Params(contextParam.Clone(), requestParam.Clone()).Error().Block(
+					jen.If(
+						jen.List(jen.Id("schemes"), jen.Id("found")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("securityKey").Values()).Assert(jen.Index().String()),
+						jen.Id("found").Op("&&").Op("!").Qual("slices", "Contains").Call(jen.Id("schemes"), jen.Id("scheme")),
+					).Block(jen.Return(jen.Nil())),
+					jen.Return(jen.Id("apply").Call(jen.Id("ctx"), jen.Id("req"))),
+				),
+			)),
+		)
+
+	hasOAuth2 := false
+	for _, scheme := range schemes {
+		switch scheme.kind {
+		case securityKindAPIKey:
+			g.generateAPIKeySecurity(f, scheme, schemes)
+		case securityKindBearer:
+			g.generateBearerSecurity(f, scheme, schemes)
+		case securityKindBasic:
+			g.generateBasicSecurity(f, scheme, schemes)
+		case securityKindOAuth2:
+			g.generateOAuth2Security(f, scheme, schemes)
+			hasOAuth2 = true
+		}
+	}
+	if hasOAuth2 {
+		g.generateOAuth2TokenSource(f)
+	}
+	return true
+}
+
+// tokenInterceptor returns the code of an interceptor fetching credentials from `source` and applying them with
+// the given statements, which can refer to them as `token`.
+func tokenInterceptor(scheme securityScheme, apply ...jen.Code) jen.Code {
+	return jen.Return(jen.Id("securityInterceptor").Call(
+		jen.Lit(scheme.name),
+		jen.Func().Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("req").Op("*").Qual("net/http", "Request")).Error().
+			BlockFunc(func(group *jen.Group) {
+				group.List(jen.Id("token"), jen.Err()).Op(":=").Id("source").Dot("Token").Call(jen.Id("ctx"))
+				group.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit(fmt.Sprintf("failed to get %s credentials", scheme.name)))),
+				)
+				for _, code := range apply {
+					group.Add(code)
+				}
+				group.Return(jen.Nil())
+			}),
+	))
+}
+
+func (g *Generator) generateAPIKeySecurity(f *jen.File, scheme securityScheme, schemes []securityScheme) {
+	name, sourceName := scheme.optionName(schemes, ""), scheme.optionName(schemes, "Source")
+	configOption := jen.Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config"))
+
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the given API key.", name, scheme.name)
+	f.Func().Id(name).Params(jen.Id("key").String()).Add(configOption.Clone()).Block(
+		jen.Return(jen.Id(sourceName).Call(jen.Id("StaticToken").Call(jen.Id("key")))),
+	)
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the API keys provided by source,", sourceName, scheme.name)
+	var apply []jen.Code
+	location := "header"
+	switch scheme.scheme.In {
+	case "query":
+		location = "query parameter"
+		apply = slices.Of[jen.Code](
+			jen.Id("query").Op(":=").Id("req").Dot("URL").Dot("Query").Call(),
+			jen.Id("query").Dot("Set").Call(jen.Lit(scheme.scheme.Name), jen.Id("token")),
+			jen.Id("req").Dot("URL").Dot("RawQuery").Op("=").Id("query").Dot("Encode").Call(),
+		)
+	case "cookie":
+		location = "cookie"
+		apply = slices.Of[jen.Code](
+			jen.Id("req").Dot("AddCookie").Call(jen.Op("&").Qual("net/http", "Cookie").Values(jen.Dict{
+				jen.Id("Name"):  jen.Lit(scheme.scheme.Name),
+				jen.Id("Value"): jen.Id("token"),
+			})),
+		)
+	default:
+		apply = slices.Of[jen.Code](
+			jen.Id("req").Dot("Header").Dot("Set").Call(jen.Lit(scheme.scheme.Name), jen.Id("token")),
+		)
+	}
+	f.Commentf("sent as the `%s` %s.", scheme.scheme.Name, location)
+	f.Func().Id(sourceName).Params(jen.Id("source").Id("TokenSource")).Add(configOption.Clone()).Block(
+		tokenInterceptor(scheme, apply...),
+	)
+}
+
+func (g *Generator) generateBearerSecurity(f *jen.File, scheme securityScheme, schemes []securityScheme) {
+	name, sourceName := scheme.optionName(schemes, ""), scheme.optionName(schemes, "Source")
+	configOption := jen.Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config"))
+
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the given bearer token.", name, scheme.name)
+	f.Func().Id(name).Params(jen.Id("token").String()).Add(configOption.Clone()).Block(
+		jen.Return(jen.Id(sourceName).Call(jen.Id("StaticToken").Call(jen.Id("token")))),
+	)
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the bearer tokens provided by", sourceName, scheme.name)
+	f.Comment("source.")
+	f.Func().Id(sourceName).Params(jen.Id("source").Id("TokenSource")).Add(configOption.Clone()).Block(
+		tokenInterceptor(scheme,
+			jen.Id("req").Dot("Header").Dot("Set").Call(jen.Lit("Authorization"), jen.Lit("Bearer ").Op("+").Id("token")),
+		),
+	)
+}
+
+func (g *Generator) generateBasicSecurity(f *jen.File, scheme securityScheme, schemes []securityScheme) {
+	name := scheme.optionName(schemes, "")
+
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the given credentials.", name, scheme.name)
+	f.Func().Id(name).Params(jen.List(jen.Id("username"), jen.Id("password")).String()).
+		Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config")).
+		Block(
+			jen.Return(jen.Id("securityInterceptor").Call(
+				jen.Lit(scheme.name),
+				jen.Func().Params(jen.Id("_").Qual("context", "Context"), jen.Id("req").Op("*").Qual("net/http", "Request")).Error().Block(
+					jen.Id("req").Dot("SetBasicAuth").Call(jen.Id("username"), jen.Id("password")),
+					jen.Return(jen.Nil()),
+				),
+			)),
+		)
+}
+
+func (g *Generator) generateOAuth2Security(f *jen.File, scheme securityScheme, schemes []securityScheme) {
+	sourceName := scheme.optionName(schemes, "TokenSource")
+	configOption := jen.Qual(optPackage, "Option").Index(jen.Qual(sdkPackage, "Config"))
+
+	f.Commentf("%s authenticates the operations requiring the `%s` scheme with the access tokens provided by", sourceName, scheme.name)
+	f.Comment("source.")
+	f.Func().Id(sourceName).Params(jen.Id("source").Id("TokenSource")).Add(configOption.Clone()).Block(
+		tokenInterceptor(scheme,
+			jen.Id("req").Dot("Header").Dot("Set").Call(jen.Lit("Authorization"), jen.Lit("Bearer ").Op("+").Id("token")),
+		),
+	)
+	flows := scheme.scheme.Flows
+	if flows == nil {
+		return
+	}
+	if flows.ClientCredentials != nil && flows.ClientCredentials.TokenUrl != "" {
+		name := scheme.optionName(schemes, "ClientCredentials")
+		f.Commentf("%s authenticates the operations requiring the `%s` scheme through the client credentials", name, scheme.name)
+		f.Comment("flow, fetching access tokens when needed.")
+		f.Func().Id(name).
+			Params(jen.List(jen.Id("clientID"), jen.Id("clientSecret")).String(), jen.Id("scopes").Op("...").String()).
+			Add(configOption.Clone()).
+			Block(
+				jen.Return(jen.Id(sourceName).Call(jen.Id("NewClientCredentialsTokenSource").Call(
+					jen.Lit(flows.ClientCredentials.TokenUrl), jen.Id("clientID"), jen.Id("clientSecret"), jen.Id("scopes").Op("..."),
+				))),
+			)
+	}
+	// refresh tokens are exchanged at the refresh URL of the flow that issued them, or at its token URL
+	refreshURL := ""
+	for _, flow := range []*v3.OAuthFlow{flows.AuthorizationCode, flows.Password, flows.ClientCredentials} {
+		if flow == nil {
+			continue
+		}
+		if refreshURL = flow.RefreshUrl; refreshURL == "" {
+			refreshURL = flow.TokenUrl
+		}
+		if refreshURL != "" {
+			break
+		}
+	}
+	if refreshURL != "" {
+		name := scheme.optionName(schemes, "RefreshToken")
+		f.Commentf("%s authenticates the operations requiring the `%s` scheme with access tokens obtained from", name, scheme.name)
+		f.Comment("the given refresh token, fetching new ones when needed.")
+		f.Func().Id(name).
+			Params(jen.List(jen.Id("clientID"), jen.Id("clientSecret"), jen.Id("refreshToken")).String()).
+			Add(configOption.Clone()).
+			Block(
+				jen.Return(jen.Id(sourceName).Call(jen.Id("NewRefreshTokenSource").Call(
+					jen.Lit(refreshURL), jen.Id("clientID"), jen.Id("clientSecret"), jen.Id("refreshToken"),
+				))),
+			)
+	}
+}
+
+// generateOAuth2TokenSource emits the TokenSource fetching access tokens from an OAuth2 token endpoint.
+func (g *Generator) generateOAuth2TokenSource(f *jen.File) {
+	httpPackage := "net/http"
+	source := jen.Id("s")
+
+	f.Comment("OAuth2TokenSource fetches access tokens from an OAuth2 token endpoint, through either the client credentials")
+	f.Comment("or the refresh token grant, and caches them until they expire.")
+	f.Type().Id("OAuth2TokenSource").Struct(
+		jen.Id("TokenURL").String(),
+		jen.Id("ClientID").String(),
+		jen.Id("ClientSecret").String(),
+		jen.Id("Scopes").Index().String(),
+		jen.Comment("RefreshToken selects the refresh token grant when set, it is updated when the server rotates it."),
+		jen.Id("RefreshToken").String(),
+		jen.Comment("HTTPClient is used to reach the token endpoint, http.DefaultClient is used when nil."),
+		jen.Id("HTTPClient").Op("*").Qual(httpPackage, "Client"),
+		jen.Line(),
+		jen.Id("mutex").Qual("sync", "Mutex"),
+		jen.Id("token").String(),
+		jen.Id("expiry").Qual("time", "Time"),
+	)
+
+	f.Comment("NewClientCredentialsTokenSource returns an OAuth2TokenSource using the client credentials grant.")
+	f.Func().Id("NewClientCredentialsTokenSource").
+		Params(jen.List(jen.Id("tokenURL"), jen.Id("clientID"), jen.Id("clientSecret")).String(), jen.Id("scopes").Op("...").String()).
+		Op("*").Id("OAuth2TokenSource").
+		Block(jen.Return(jen.Op("&").Id("OAuth2TokenSource").Values(jen.Dict{
+			jen.Id("TokenURL"):     jen.Id("tokenURL"),
+			jen.Id("ClientID"):     jen.Id("clientID"),
+			jen.Id("ClientSecret"): jen.Id("clientSecret"),
+			jen.Id("Scopes"):       jen.Id("scopes"),
+		})))
+
+	f.Comment("NewRefreshTokenSource returns an OAuth2TokenSource using the refresh token grant.")
+	f.Func().Id("NewRefreshTokenSource").
+		Params(jen.List(jen.Id("tokenURL"), jen.Id("clientID"), jen.Id("clientSecret"), jen.Id("refreshToken")).String()).
+		Op("*").Id("OAuth2TokenSource").
+		Block(jen.Return(jen.Op("&").Id("OAuth2TokenSource").Values(jen.Dict{
+			jen.Id("TokenURL"):     jen.Id("tokenURL"),
+			jen.Id("ClientID"):     jen.Id("clientID"),
+			jen.Id("ClientSecret"): jen.Id("clientSecret"),
+			jen.Id("RefreshToken"): jen.Id("refreshToken"),
+		})))
+
+	f.Comment("Token returns the cached access token, or fetches a new one when it is about to expire.")
+	f.Func().Params(source.Clone().Op("*").Id("OAuth2TokenSource")).Id("Token").
+		Params(jen.Id("ctx").Qual("context", "Context")).
+		Parens(jen.List(jen.String(), jen.Error())).
+		BlockFunc(func(group *jen.Group) {
+			group.Add(source.Clone()).Dot("mutex").Dot("Lock").Call()
+			group.Defer().Add(source.Clone()).Dot("mutex").Dot("Unlock").Call()
+			group.If(
+				source.Clone().Dot("token").Op("!=").Lit("").Op("&&").Parens(
+					source.Clone().Dot("expiry").Dot("IsZero").Call().Op("||").
+						Qual("time", "Now").Call().Dot("Before").Call(source.Clone().Dot("expiry")),
+				),
+			).Block(jen.Return(source.Clone().Dot("token"), jen.Nil()))
+
+			group.Id("form").Op(":=").Qual("net/url", "Values").Values()
+			group.If(source.Clone().Dot("RefreshToken").Op("!=").Lit("")).Block(
+				jen.Id("form").Dot("Set").Call(jen.Lit("grant_type"), jen.Lit("refresh_token")),
+				jen.Id("form").Dot("Set").Call(jen.Lit("refresh_token"), source.Clone().Dot("RefreshToken")),
+			).Else().Block(
+				jen.Id("form").Dot("Set").Call(jen.Lit("grant_type"), jen.Lit("client_credentials")),
+			)
+			group.If(jen.Len(source.Clone().Dot("Scopes")).Op(">").Lit(0)).Block(
+				jen.Id("form").Dot("Set").Call(jen.Lit("scope"), jen.Qual("strings", "Join").Call(source.Clone().Dot("Scopes"), jen.Lit(" "))),
+			)
+			group.List(jen.Id("req"), jen.Err()).Op(":=").Qual(httpPackage, "NewRequestWithContext").Call(
+				jen.Id("ctx"), jen.Qual(httpPackage, "MethodPost"), source.Clone().Dot("TokenURL"),
+				jen.Qual("strings", "NewReader").Call(jen.Id("form").Dot("Encode").Call()),
+			)
+			group.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Lit(""), jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit("failed to create token request"))),
+			)
+			group.Id("req").Dot("Header").Dot("Set").Call(jen.Lit("Content-Type"), jen.Lit("application/x-www-form-urlencoded"))
+			group.Id("req").Dot("Header").Dot("Set").Call(jen.Lit("Accept"), jen.Lit("application/json"))
+			group.Id("req").Dot("SetBasicAuth").Call(
+				jen.Qual("net/url", "QueryEscape").Call(source.Clone().Dot("ClientID")),
+				jen.Qual("net/url", "QueryEscape").Call(source.Clone().Dot("ClientSecret")),
+			)
+			group.Id("httpClient").Op(":=").Add(source.Clone()).Dot("HTTPClient")
+			group.If(jen.Id("httpClient").Op("==").Nil()).Block(
+				jen.Id("httpClient").Op("=").Qual(httpPackage, "DefaultClient"),
+			)
+			group.List(jen.Id("resp"), jen.Err()).Op(":=").Id("httpClient").Dot("Do").Call(jen.Id("req"))
+			group.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Lit(""), jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit("failed to reach token endpoint %s"), source.Clone().Dot("TokenURL"))),
+			)
+			group.Defer().Id("resp").Dot("Body").Dot("Close").Call()
+			group.If(jen.Id("resp").Dot("StatusCode").Op("!=").Qual(httpPackage, "StatusOK")).Block(
+				jen.List(jen.Id("body"), jen.Id("_")).Op(":=").Qual("io", "ReadAll").Call(jen.Id("resp").Dot("Body")),
+				jen.Return(jen.Lit(""), jen.Qual(errPackage, "Newf").Call(
+					jen.Lit("token endpoint replied with '%d' status: %s"), jen.Id("resp").Dot("StatusCode"), jen.String().Parens(jen.Id("body")),
+				)),
+			)
+			group.Var().Id("token").Struct(
+				jen.Id("AccessToken").String().Tag(map[string]string{"json": "access_token"}),
+				jen.Id("ExpiresIn").Int64().Tag(map[string]string{"json": "expires_in"}),
+				jen.Id("RefreshToken").String().Tag(map[string]string{"json": "refresh_token"}),
+			)
+			group.If(
+				jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(jen.Id("resp").Dot("Body")).Dot("Decode").Call(jen.Op("&").Id("token")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Lit(""), jen.Qual(errPackage, "Wrapf").Call(jen.Err(), jen.Lit("failed to decode token response"))),
+			)
+			group.If(jen.Id("token").Dot("AccessToken").Op("==").Lit("")).Block(
+				jen.Return(jen.Lit(""), jen.Qual(errPackage, "Newf").Call(jen.Lit("token endpoint replied without an access token"))),
+			)
+			group.Add(source.Clone()).Dot("token").Op("=").Id("token").Dot("AccessToken")
+			group.Add(source.Clone()).Dot("expiry").Op("=").Qual("time", "Time").Values()
+			group.If(jen.Id("token").Dot("ExpiresIn").Op(">").Lit(0)).Block(
+				jen.Comment("renew tokens slightly ahead of their expiry, to account for clock skew and latency"),
+				source.Clone().Dot("expiry").Op("=").Qual("time", "Now").Call().Dot("Add").Call(
+					jen.Qual("time", "Duration").Call(jen.Id("token").Dot("ExpiresIn")).Op("*").Qual("time", "Second").Op("-").Lit(10).Op("*").Qual("time", "Second"),
+				),
+			)
+			group.If(jen.Id("token").Dot("RefreshToken").Op("!=").Lit("").Op("&&").Add(source.Clone()).Dot("RefreshToken").Op("!=").Lit("")).Block(
+				source.Clone().Dot("RefreshToken").Op("=").Id("token").Dot("RefreshToken"),
+			)
+			group.Return(source.Clone().Dot("token"), jen.Nil())
+		})
+}
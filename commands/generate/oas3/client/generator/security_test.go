@@ -0,0 +1,15 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestSecurityGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "security", spec: "security"},
+	})
+}
+
+func TestSecurityOptions(t *testing.T) {
+	runBehaviour(t, goldenCase{name: "security", spec: "security"}, "security")
+}
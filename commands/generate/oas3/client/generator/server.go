@@ -0,0 +1,483 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanced/caps"
+	"github.com/dave/jennifer/jen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+const (
+	humaPackage   = "github.com/danielgtaylor/huma/v2"
+	apiPackage    = "github.com/kiwiworks/rodent/web/api"
+	httpPackage   = "github.com/kiwiworks/rodent/web/http"
+	appPackage    = "github.com/kiwiworks/rodent/app"
+	modulePackage = "github.com/kiwiworks/rodent/app/module"
+)
+
+// serverOperation is an operation implemented by one of the generated services.
+type serverOperation struct {
+	service     string
+	methodName  string
+	method      string
+	apiPath     string
+	operation   *v3.Operation
+	inputName   string
+	output      *jen.Statement
+	status      int
+	security    []map[string][]string
+	description string
+}
+
+// serviceName names the interface implementing the operations of the tag, operations without any tag being
+// implemented by the `DefaultService`.
+func serviceName(operation *v3.Operation) string {
+	if len(operation.Tags) == 0 {
+		return "DefaultService"
+	}
+	fragments := strings.FieldsFunc(operation.Tags[0], func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' ' || r == '/'
+	})
+	return strings.Join(slices.Map(fragments, func(in string) string { return caps.ToCamel(in) }), "") + "Service"
+}
+
+// successResponse returns the status code and the response of the successful outcome of the operation, which is
+// the lowest documented 2XX status code, or the default response with a 200 status code.
+func successResponse(operation *v3.Operation) (int, *v3.Response) {
+	if operation.Responses == nil {
+		return 200, nil
+	}
+	if operation.Responses.Codes != nil {
+		codes := make([]int, 0)
+		for code := range operation.Responses.Codes.KeysFromOldest() {
+			if statusCode, err := strconv.Atoi(code); err == nil && statusCode >= 200 && statusCode < 300 {
+				codes = append(codes, statusCode)
+			}
+		}
+		if len(codes) > 0 {
+			sort.Ints(codes)
+			return codes[0], operation.Responses.Codes.Value(strconv.Itoa(codes[0]))
+		}
+	}
+	return 200, operation.Responses.Default
+}
+
+// operationSecurityRequirements returns the security requirements of the operation, from its own requirements or the
+// ones of the document. Empty requirements, making the authentication optional, are left out since the rodent auth
+// middleware cannot express them.
+func operationSecurityRequirements(document v3.Document, operation *v3.Operation) []map[string][]string {
+	requirements := document.Security
+	if operation.Security != nil {
+		requirements = operation.Security
+	}
+	security := make([]map[string][]string, 0)
+	for _, requirement := range requirements {
+		if requirement.Requirements == nil || requirement.Requirements.Len() == 0 {
+			continue
+		}
+		schemes := make(map[string][]string)
+		for name := range requirement.Requirements.KeysFromOldest() {
+			schemes[name] = append([]string{}, requirement.Requirements.Value(name)...)
+		}
+		security = append(security, schemes)
+	}
+	if len(security) == 0 {
+		return nil
+	}
+	return security
+}
+
+// paramValidationTags returns the huma struct tags validating a parameter against its schema.
+func (g *Generator) paramValidationTags(schema *base.Schema) map[string]string {
+	tags := make(map[string]string)
+	formatFloat := func(value float64) string { return strconv.FormatFloat(value, 'f', -1, 64) }
+	if schema.Minimum != nil {
+		tags["minimum"] = formatFloat(*schema.Minimum)
+	}
+	if schema.Maximum != nil {
+		tags["maximum"] = formatFloat(*schema.Maximum)
+	}
+	if schema.MinLength != nil {
+		tags["minLength"] = strconv.FormatInt(*schema.MinLength, 10)
+	}
+	if schema.MaxLength != nil {
+		tags["maxLength"] = strconv.FormatInt(*schema.MaxLength, 10)
+	}
+	if schema.MinItems != nil {
+		tags["minItems"] = strconv.FormatInt(*schema.MinItems, 10)
+	}
+	if schema.MaxItems != nil {
+		tags["maxItems"] = strconv.FormatInt(*schema.MaxItems, 10)
+	}
+	if schema.Pattern != "" {
+		tags["pattern"] = schema.Pattern
+	}
+	if nodes := g.enumNodes(schema); len(nodes) > 0 {
+		tags["enum"] = strings.Join(slices.Map(nodes, func(in *yaml.Node) string { return in.Value }), ",")
+	}
+	if schema.Default != nil && schema.Default.Value != "" {
+		tags["default"] = schema.Default.Value
+	}
+	return tags
+}
+
+// generateServerInput emits the `<Method>Input` struct binding the parameters and the request body of the operation,
+// which huma validates before calling the service. It reports false when the operation has a request body without
+// any JSON content.
+func (g *Generator) generateServerInput(f *jen.File, methodName string, pathItem *v3.PathItem, operation *v3.Operation) (bool, error) {
+	log := logger.New()
+	fields := make([]jen.Code, 0)
+	usedNames := make(map[string]bool)
+
+	var body *jen.Statement
+	if operation.RequestBody != nil && operation.RequestBody.Content != nil && operation.RequestBody.Content.Len() > 0 {
+		mediaType := operation.RequestBody.Content.Value("application/json")
+		if mediaType == nil || mediaType.Schema == nil {
+			return false, nil
+		}
+		schema, err := mediaType.Schema.BuildSchema()
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid request body schema")
+		}
+		required := operation.RequestBody.Required != nil && *operation.RequestBody.Required
+		// huma only requires bodies which are not pointers
+		body = jen.Id("Body")
+		if !required && !slices.Contains(schema.Type, "array") {
+			body.Op("*")
+		}
+		if err := g.oas3TypeToGoType(body, mediaType.Schema, schema, methodName+"Request"); err != nil {
+			return false, errors.Wrapf(err, "invalid request body type")
+		}
+		usedNames["Body"] = true
+	}
+
+	for _, param := range operationParameters(pathItem, operation) {
+		if !slices.Contains(slices.Of("path", "query", "header", "cookie"), param.In) {
+			continue
+		}
+		goName := caps.ToCamel(param.Name)
+		if usedNames[goName] {
+			goName += caps.ToCamel(param.In)
+		}
+		usedNames[goName] = true
+		stmt := jen.Id(goName)
+		tags := map[string]string{}
+		switch {
+		case param.Schema == nil:
+			log.Warn("binding parameter without schema as a string, content based parameters are not supported",
+				zap.String("parameter", param.Name))
+			stmt.String()
+		default:
+			schema, err := param.Schema.BuildSchema()
+			if err != nil {
+				return false, errors.Wrapf(err, "invalid schema for %s parameter %s", param.In, param.Name)
+			}
+			if slices.Contains(schema.Type, "object") {
+				log.Warn("binding object parameter as a string, only primitives and arrays of primitives are supported",
+					zap.String("parameter", param.Name))
+				stmt.String()
+				break
+			}
+			// huma does not support pointers, absent optional parameters are left to their zero value
+			if err := g.oas3TypeToGoType(stmt, param.Schema, schema, methodName+goName); err != nil {
+				return false, errors.Wrapf(err, "invalid type for %s parameter %s", param.In, param.Name)
+			}
+			tags = g.paramValidationTags(schema)
+			if slices.Contains(schema.Type, "array") {
+				delete(tags, "default")
+				if param.In == "query" && paramExploded(param) {
+					// huma expects comma separated values unless told otherwise
+					tags["explode"] = "true"
+				}
+			}
+		}
+		tags[param.In] = param.Name
+		if tags["explode"] == "true" {
+			delete(tags, "explode")
+			tags[param.In] += ",explode"
+		}
+		if param.Required != nil && *param.Required && param.In != "path" {
+			tags["required"] = "true"
+			delete(tags, "default")
+		}
+		if description := strings.TrimSpace(param.Description); description != "" {
+			tags["doc"] = strings.Join(strings.Fields(description), " ")
+		}
+		fields = append(fields, stmt.Tag(tags))
+	}
+	if body != nil {
+		fields = append(fields, body)
+	}
+
+	structName := methodName + "Input"
+	f.Commentf("%s holds the parameters and the request body of the %s operation.", structName, methodName)
+	f.Type().Id(structName).Struct(fields...)
+	return true, nil
+}
+
+// generateServerOperation emits the input of the operation, and returns how its service implements it. It returns
+// nil when the operation cannot be served.
+func (g *Generator) generateServerOperation(f *jen.File, method, apiPath string, pathItem *v3.PathItem, operation *v3.Operation) (*serverOperation, error) {
+	log := logger.New().With(props.HttpMethod(method), props.HttpPath(apiPath))
+	methodName := g.methodName(operation)
+	status, response := successResponse(operation)
+	output := jen.Op("*").Qual(httpPackage, "Empty")
+	if response != nil && response.Content != nil && response.Content.Len() > 0 {
+		mediaType := response.Content.Value("application/json")
+		if mediaType == nil || mediaType.Schema == nil {
+			log.Warn("skipping operation without a response with content type application/json")
+			return nil, nil
+		}
+		body := jen.Null()
+		if err := g.schemaProxyToGoType(body, mediaType.Schema, methodName+"Response"); err != nil {
+			return nil, errors.Wrapf(err, "invalid response type")
+		}
+		output = jen.Op("*").Qual(apiPackage, "Response").Index(body)
+	}
+
+	ok, err := g.generateServerInput(f, methodName, pathItem, operation)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Warn("skipping operation without a request body with content type application/json")
+		return nil, nil
+	}
+
+	description := operation.Description
+	if description == "" {
+		description = operation.Summary
+	}
+	return &serverOperation{
+		service:     serviceName(operation),
+		methodName:  methodName,
+		method:      method,
+		apiPath:     apiPath,
+		operation:   operation,
+		inputName:   methodName + "Input",
+		output:      output,
+		status:      status,
+		security:    operationSecurityRequirements(g.model.Model, operation),
+		description: description,
+	}, nil
+}
+
+// signature returns the parameters and the results of the service method implementing the operation.
+func (o *serverOperation) signature() (jen.Code, jen.Code) {
+	return jen.Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("input").Op("*").Id(o.inputName),
+		), jen.Parens(jen.List(
+			o.output.Clone(),
+			jen.Error(),
+		))
+}
+
+// humaOperation returns the literal of the huma operation mounted for the operation.
+func (o *serverOperation) humaOperation() jen.Code {
+	operationId := o.operation.OperationId
+	if operationId == "" {
+		operationId = caps.ToLowerCamel(o.methodName)
+	}
+	values := jen.Dict{
+		jen.Id("OperationID"):   jen.Lit(operationId),
+		jen.Id("Method"):        jen.Lit(o.method),
+		jen.Id("Path"):          jen.Lit(o.apiPath),
+		jen.Id("DefaultStatus"): jen.Lit(o.status),
+	}
+	if o.operation.Summary != "" {
+		values[jen.Id("Summary")] = jen.Lit(o.operation.Summary)
+	}
+	if o.operation.Description != "" {
+		values[jen.Id("Description")] = jen.Lit(o.operation.Description)
+	}
+	if len(o.operation.Tags) > 0 {
+		values[jen.Id("Tags")] = jen.Index().String().ValuesFunc(func(group *jen.Group) {
+			for _, tag := range o.operation.Tags {
+				group.Lit(tag)
+			}
+		})
+	}
+	if o.operation.Deprecated != nil && *o.operation.Deprecated {
+		values[jen.Id("Deprecated")] = jen.True()
+	}
+	if o.security != nil {
+		values[jen.Id("Security")] = jen.Index().Map(jen.String()).Index().String().ValuesFunc(func(group *jen.Group) {
+			for _, requirement := range o.security {
+				names := make([]string, 0, len(requirement))
+				for name := range requirement {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				group.Values(jen.DictFunc(func(dict jen.Dict) {
+					for _, name := range names {
+						dict[jen.Lit(name)] = jen.Index().String().ValuesFunc(func(group *jen.Group) {
+							for _, scope := range requirement[name] {
+								group.Lit(scope)
+							}
+						})
+					}
+				}))
+			}
+		})
+	}
+	return jen.Qual(humaPackage, "Operation").Values(values)
+}
+
+// generateHandlerHelper emits the `newHandler` function turning an operation and its implementation into a rodent
+// handler.
+func (g *Generator) generateHandlerHelper(f *jen.File) {
+	f.Comment("newHandler returns the handler mounting the operation on the rodent web server, the errors of its")
+	f.Comment("implementation being converted by the configured error converter.")
+	f.Func().Id("newHandler").Types(jen.List(jen.Id("I"), jen.Id("O")).Any()).
+		Params(
+			jen.Id("operation").Qual(humaPackage, "Operation"),
+			jen.Id("impl").Func().Params(jen.Qual("context", "Context"), jen.Op("*").Id("I")).
+				Parens(jen.List(jen.Op("*").Id("O"), jen.Error())),
+		).
+		Op("*").Qual(apiPackage, "Handler").
+		Block(jen.Return(jen.Op("&").Qual(apiPackage, "Handler").Values(jen.Dict{
+			jen.Id("Options"): jen.Qual(apiPackage, "Options").Values(jen.Dict{
+				jen.Id("Method"):       jen.Qual(httpPackage, "Method").Call(jen.Id("operation").Dot("Method")),
+				jen.Id("Path"):         jen.Id("operation").Dot("Path"),
+				jen.Id("RegisterOas3"): jen.True(),
+				jen.Id("OperationId"):  jen.Id("operation").Dot("OperationID"),
+				jen.Id("ContentType"):  jen.Lit("application/json; charset=utf-8"),
+				jen.Id("Tags"):         jen.Id("operation").Dot("Tags"),
+				jen.Id("Protected"):    jen.Len(jen.Id("operation").Dot("Security")).Op(">").Lit(0),
+				jen.Id("Description"):  jen.Id("operation").Dot("Description"),
+			}),
+			jen.Id("Mount"): jen.Func().
+				Params(jen.Id("humaAPI").Qual(humaPackage, "API"), jen.Id("config").Qual(apiPackage, "Config")).
+				Block(
+					jen.Qual(humaPackage, "Register").Call(
+						jen.Id("humaAPI"),
+						jen.Id("operation"),
+						jen.Func().
+							Params(jen.Id("ctx").Qual("context", "Context"), jen.Id("input").Op("*").Id("I")).
+							Parens(jen.List(jen.Op("*").Id("O"), jen.Error())).
+							Block(
+								jen.List(jen.Id("output"), jen.Err()).Op(":=").Id("impl").Call(jen.Id("ctx"), jen.Id("input")),
+								jen.If(jen.Err().Op("!=").Nil()).Block(
+									jen.Return(jen.Nil(), jen.Id("config").Dot("ErrorConverter").Call(jen.Err())),
+								),
+								jen.Return(jen.Id("output"), jen.Nil()),
+							),
+					),
+				),
+		})))
+}
+
+// generateService emits the interface of the service implementing the operations of a tag, along with its
+// unimplemented variant.
+func (g *Generator) generateService(f *jen.File, document v3.Document, name string, operations []*serverOperation) {
+	tag := ""
+	if len(operations[0].operation.Tags) > 0 {
+		tag = operations[0].operation.Tags[0]
+	}
+	if tag == "" {
+		f.Commentf("%s implements the operations without any tag.", name)
+	} else {
+		f.Commentf("%s implements the operations tagged `%s`.", name, tag)
+		for _, documentTag := range document.Tags {
+			if documentTag.Name == tag && documentTag.Description != "" {
+				f.Comment(strings.TrimSpace(documentTag.Description))
+			}
+		}
+	}
+	f.Type().Id(name).InterfaceFunc(func(group *jen.Group) {
+		for _, op := range operations {
+			group.Commentf("%s handles the %s %s operation.", op.methodName, op.method, op.apiPath)
+			if op.description != "" {
+				group.Comment(strings.TrimSpace(op.description))
+			}
+			params, results := op.signature()
+			group.Id(op.methodName).Add(params, results)
+		}
+	})
+
+	unimplemented := "Unimplemented" + name
+	f.Commentf("%s can be embedded into the implementations of %s, the operations it does not implement", unimplemented, name)
+	f.Comment("reply with a 501 Not Implemented status.")
+	f.Type().Id(unimplemented).Struct()
+	for _, op := range operations {
+		params, results := op.signature()
+		f.Func().Params(jen.Id(unimplemented)).Id(op.methodName).Add(params, results).Block(
+			jen.Return(jen.Nil(), jen.Qual(humaPackage, "Error501NotImplemented").Call(
+				jen.Lit(fmt.Sprintf("%s %s is not implemented", op.method, op.apiPath)),
+			)),
+		)
+		f.Line()
+	}
+}
+
+func (g *Generator) generateServerPackage(document v3.Document) error {
+	// the server lives next to the `dtos` package, so that a client generated into the same output directory shares it
+	f := g.generatePackageFile(g.flags.PackageName, g.flags.PackageName, "server")
+	inputs := g.generatePackageFile(g.flags.PackageName, g.flags.PackageName, "inputs")
+	f.ImportName(humaPackage, "huma")
+	inputs.ImportName(humaPackage, "huma")
+
+	services := make(map[string][]*serverOperation)
+	serviceNames := make([]string, 0)
+	for _, apiPath := range orderedKeys(g.flags.Ordering, document.Paths.PathItems) {
+		pathItem := document.Paths.PathItems.Value(apiPath)
+		for _, op := range pathOperations(pathItem) {
+			if !g.isOperationSelected(apiPath, op.operation) {
+				continue
+			}
+			generated, err := g.generateServerOperation(inputs, op.method, apiPath, pathItem, op.operation)
+			if err != nil {
+				return errors.Wrapf(err, "failed to generate server %s operation for %s", op.method, apiPath)
+			}
+			if generated == nil {
+				continue
+			}
+			if _, exists := services[generated.service]; !exists {
+				serviceNames = append(serviceNames, generated.service)
+			}
+			services[generated.service] = append(services[generated.service], generated)
+		}
+	}
+	if g.flags.Ordering == OrderingSorted {
+		sort.Strings(serviceNames)
+	}
+
+	for _, name := range serviceNames {
+		g.generateService(f, document, name, services[name])
+	}
+
+	g.generateHandlerHelper(f)
+	constructors := make([]jen.Code, 0)
+	for _, name := range serviceNames {
+		for _, op := range services[name] {
+			constructor := "New" + op.methodName + "Handler"
+			constructors = append(constructors, jen.Id(constructor))
+			f.Commentf("%s returns the handler of the %s %s operation, implemented by the %s.", constructor, op.method, op.apiPath, name)
+			f.Func().Id(constructor).Params(jen.Id("service").Id(name)).Op("*").Qual(apiPackage, "Handler").Block(
+				jen.Return(jen.Id("newHandler").Call(op.humaOperation(), jen.Id("service").Dot(op.methodName))),
+			)
+		}
+	}
+
+	f.Comment("Handlers registers the handlers of every operation with the module, mounting them on the rodent web server.")
+	if len(serviceNames) > 0 {
+		f.Commentf("The application must provide the services implementing them: %s.", strings.Join(serviceNames, ", "))
+	}
+	f.Func().Id("Handlers").Params().Qual(optPackage, "Option").Index(jen.Qual(appPackage, "Module")).Block(
+		jen.Return(jen.Qual(modulePackage, "Handlers").Call(constructors...)),
+	)
+	return nil
+}
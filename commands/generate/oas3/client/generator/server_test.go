@@ -0,0 +1,15 @@
+package generator
+
+import (
+	"testing"
+)
+
+var serverCase = goldenCase{name: "petstore_server", spec: "petstore", flags: func(flags *Flags) { flags.Target = TargetServer }}
+
+func TestServerGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{serverCase})
+}
+
+func TestServer(t *testing.T) {
+	runBehaviour(t, serverCase, "server")
+}
@@ -0,0 +1,706 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// swaggerOperationMethods lists the operations a Swagger 2.0 path item can hold.
+var swaggerOperationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// swaggerSchemaKeys lists the keys of a Swagger 2.0 non-body parameter, header or items object that belong to the
+// schema of their OpenAPI 3 counterpart.
+var swaggerSchemaKeys = []string{
+	"type", "format", "items", "default", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "maxLength",
+	"minLength", "pattern", "maxItems", "minItems", "uniqueItems", "enum", "multipleOf",
+}
+
+// swaggerRefPrefixes maps the prefixes of Swagger 2.0 references to their OpenAPI 3 counterpart.
+var swaggerRefPrefixes = map[string]string{
+	"#/definitions/": "#/components/schemas/",
+	"#/parameters/":  "#/components/parameters/",
+	"#/responses/":   "#/components/responses/",
+}
+
+// isSwaggerVersion reports whether the version reported by libopenapi is the one of a Swagger 2.0 document.
+func isSwaggerVersion(version string) bool {
+	return strings.HasPrefix(version, "2.")
+}
+
+// swaggerConverter converts a Swagger 2.0 document into an OpenAPI 3.0 one, keeping track of the information lost in
+// the process.
+type swaggerConverter struct {
+	root     *yaml.Node
+	consumes []string
+	produces []string
+	// parameters holds the global parameters, which are resolved when referenced by an operation since body and form
+	// parameters become part of its request body.
+	parameters map[string]*yaml.Node
+	warnings   []string
+}
+
+// convertSwagger converts a Swagger 2.0 document into an OpenAPI 3.0 one, and returns the conversions that lost
+// information as warnings.
+func convertSwagger(spec []byte) ([]byte, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(spec, &document); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse swagger document")
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.Newf("invalid swagger document, expected a mapping at its root")
+	}
+	c := &swaggerConverter{
+		root:       document.Content[0],
+		consumes:   stringValues(mappingValue(document.Content[0], "consumes")),
+		produces:   stringValues(mappingValue(document.Content[0], "produces")),
+		parameters: make(map[string]*yaml.Node),
+	}
+	if len(c.consumes) == 0 {
+		c.consumes = slices.Of("application/json")
+	}
+	if len(c.produces) == 0 {
+		c.produces = slices.Of("application/json")
+	}
+	converted := c.convert()
+	rewriteSwaggerNodes(converted)
+	output, err := yaml.Marshal(converted)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to render converted document")
+	}
+	return output, c.warnings, nil
+}
+
+func (c *swaggerConverter) warnf(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *swaggerConverter) convert() *yaml.Node {
+	out := mappingNode()
+	appendPair(out, "openapi", stringNode("3.0.3"))
+	appendPair(out, "info", mappingValue(c.root, "info"))
+	appendPair(out, "externalDocs", mappingValue(c.root, "externalDocs"))
+	appendPair(out, "servers", c.servers())
+	appendPair(out, "tags", mappingValue(c.root, "tags"))
+
+	components := mappingNode()
+	appendPair(components, "schemas", mappingValue(c.root, "definitions"))
+	parameters, requestBodies := c.globalParameters()
+	appendPair(components, "parameters", parameters)
+	appendPair(components, "requestBodies", requestBodies)
+	if responses := mappingValue(c.root, "responses"); responses != nil {
+		converted := mappingNode()
+		for idx := 0; idx+1 < len(responses.Content); idx += 2 {
+			name := responses.Content[idx].Value
+			appendPair(converted, name, c.response("responses."+name, responses.Content[idx+1], c.produces))
+		}
+		appendPair(components, "responses", converted)
+	}
+	appendPair(components, "securitySchemes", c.securitySchemes())
+
+	appendPair(out, "paths", c.paths())
+	if len(components.Content) > 0 {
+		appendPair(out, "components", components)
+	}
+	appendPair(out, "security", mappingValue(c.root, "security"))
+	for idx := 0; idx+1 < len(c.root.Content); idx += 2 {
+		if key := c.root.Content[idx].Value; strings.HasPrefix(key, "x-") {
+			appendPair(out, key, c.root.Content[idx+1])
+		}
+	}
+	return out
+}
+
+// servers builds the servers of the document from its host, base path and schemes.
+func (c *swaggerConverter) servers() *yaml.Node {
+	host := scalarValue(mappingValue(c.root, "host"))
+	basePath := scalarValue(mappingValue(c.root, "basePath"))
+	if host == "" && basePath == "" {
+		return nil
+	}
+	schemes := stringValues(mappingValue(c.root, "schemes"))
+	if len(schemes) == 0 {
+		schemes = slices.Of("https")
+	}
+	servers := sequenceNode()
+	for _, scheme := range schemes {
+		url := basePath
+		if host != "" {
+			url = scheme + "://" + host + basePath
+		}
+		server := mappingNode()
+		appendPair(server, "url", stringNode(url))
+		servers.Content = append(servers.Content, server)
+		if host == "" {
+			// relative servers do not depend on the scheme
+			break
+		}
+	}
+	return servers
+}
+
+// globalParameters converts the global parameters into component parameters, or request bodies for body parameters.
+// Form parameters have no OpenAPI 3 counterpart, and are inlined into the operations referencing them.
+func (c *swaggerConverter) globalParameters() (*yaml.Node, *yaml.Node) {
+	parameters, requestBodies := mappingNode(), mappingNode()
+	global := mappingValue(c.root, "parameters")
+	if global == nil {
+		return nil, nil
+	}
+	for idx := 0; idx+1 < len(global.Content); idx += 2 {
+		name, param := global.Content[idx].Value, global.Content[idx+1]
+		c.parameters[name] = param
+		switch scalarValue(mappingValue(param, "in")) {
+		case "body":
+			appendPair(requestBodies, name, c.requestBody(param, c.consumes))
+		case "formData":
+		default:
+			appendPair(parameters, name, c.parameter("parameters."+name, param))
+		}
+	}
+	if len(parameters.Content) == 0 {
+		parameters = nil
+	}
+	if len(requestBodies.Content) == 0 {
+		requestBodies = nil
+	}
+	return parameters, requestBodies
+}
+
+func (c *swaggerConverter) paths() *yaml.Node {
+	out := mappingNode()
+	paths := mappingValue(c.root, "paths")
+	if paths == nil {
+		return out
+	}
+	for idx := 0; idx+1 < len(paths.Content); idx += 2 {
+		apiPath, item := paths.Content[idx].Value, paths.Content[idx+1]
+		if ref := mappingValue(item, "$ref"); ref != nil {
+			c.warnf("paths.%s: path item references are not converted", apiPath)
+			continue
+		}
+		converted := mappingNode()
+		// body and form parameters cannot be declared on OpenAPI 3 path items, they are pushed down to the operations
+		var inherited []*yaml.Node
+		if params := mappingValue(item, "parameters"); params != nil {
+			convertedParams := sequenceNode()
+			for _, param := range params.Content {
+				switch scalarValue(mappingValue(c.resolveParameter(param), "in")) {
+				case "body", "formData":
+					inherited = append(inherited, param)
+				default:
+					convertedParams.Content = append(convertedParams.Content, c.parameter("paths."+apiPath, param))
+				}
+			}
+			if len(convertedParams.Content) > 0 {
+				appendPair(converted, "parameters", convertedParams)
+			}
+		}
+		for keyIdx := 0; keyIdx+1 < len(item.Content); keyIdx += 2 {
+			key, value := item.Content[keyIdx].Value, item.Content[keyIdx+1]
+			switch {
+			case slices.Contains(swaggerOperationMethods, key):
+				appendPair(converted, key, c.operation(fmt.Sprintf("paths.%s.%s", apiPath, key), value, inherited))
+			case strings.HasPrefix(key, "x-"):
+				appendPair(converted, key, value)
+			}
+		}
+		appendPair(out, apiPath, converted)
+	}
+	return out
+}
+
+// resolveParameter returns the global parameter a parameter references, or the parameter itself.
+func (c *swaggerConverter) resolveParameter(param *yaml.Node) *yaml.Node {
+	ref := scalarValue(mappingValue(param, "$ref"))
+	if name, found := strings.CutPrefix(ref, "#/parameters/"); found {
+		if resolved, exists := c.parameters[name]; exists {
+			return resolved
+		}
+	}
+	return param
+}
+
+func (c *swaggerConverter) operation(where string, op *yaml.Node, inherited []*yaml.Node) *yaml.Node {
+	out := mappingNode()
+	consumes := stringValues(mappingValue(op, "consumes"))
+	if len(consumes) == 0 {
+		consumes = c.consumes
+	}
+	produces := stringValues(mappingValue(op, "produces"))
+	if len(produces) == 0 {
+		produces = c.produces
+	}
+	if mappingValue(op, "schemes") != nil {
+		c.warnf("%s: operation schemes are not supported by OpenAPI 3 and were dropped", where)
+	}
+	for idx := 0; idx+1 < len(op.Content); idx += 2 {
+		key, value := op.Content[idx].Value, op.Content[idx+1]
+		switch key {
+		case "consumes", "produces", "parameters", "responses", "schemes":
+		default:
+			appendPair(out, key, value)
+		}
+	}
+
+	params := sequenceNode()
+	var body *yaml.Node
+	form := mappingNode()
+	formRequired := sequenceNode()
+	multipart := slices.Contains(consumes, "multipart/form-data")
+	opParams := append(inherited[:len(inherited):len(inherited)], sequenceValues(mappingValue(op, "parameters"))...)
+	for _, param := range opParams {
+		resolved := c.resolveParameter(param)
+		name := scalarValue(mappingValue(resolved, "name"))
+		switch scalarValue(mappingValue(resolved, "in")) {
+		case "body":
+			if body != nil {
+				c.warnf("%s: only the last body parameter is kept", where)
+			}
+			if resolved != param {
+				// global body parameters are converted into component request bodies
+				body = mappingNode()
+				appendPair(body, "$ref", stringNode("#/components/requestBodies/"+strings.TrimPrefix(scalarValue(mappingValue(param, "$ref")), "#/parameters/")))
+			} else {
+				body = c.requestBody(param, consumes)
+			}
+		case "formData":
+			schema := c.parameterSchema(where, resolved)
+			if scalarValue(mappingValue(resolved, "type")) == "file" {
+				multipart = true
+			}
+			if description := mappingValue(resolved, "description"); description != nil {
+				appendPair(schema, "description", description)
+			}
+			appendPair(form, name, schema)
+			if scalarValue(mappingValue(resolved, "required")) == "true" {
+				formRequired.Content = append(formRequired.Content, stringNode(name))
+			}
+		default:
+			params.Content = append(params.Content, c.parameter(where, param))
+		}
+	}
+	if len(params.Content) > 0 {
+		appendPair(out, "parameters", params)
+	}
+	if len(form.Content) > 0 {
+		if body != nil {
+			c.warnf("%s: form parameters cannot be combined with a body parameter, they were dropped", where)
+		} else {
+			body = c.formRequestBody(where, form, formRequired, consumes, multipart)
+		}
+	}
+	appendPair(out, "requestBody", body)
+
+	if responses := mappingValue(op, "responses"); responses != nil {
+		converted := mappingNode()
+		for idx := 0; idx+1 < len(responses.Content); idx += 2 {
+			code, response := responses.Content[idx].Value, responses.Content[idx+1]
+			if strings.HasPrefix(code, "x-") {
+				appendPair(converted, code, response)
+				continue
+			}
+			appendPair(converted, code, c.response(where+"."+code, response, produces))
+		}
+		appendPair(out, "responses", converted)
+	}
+	return out
+}
+
+// requestBody converts a body parameter into a request body accepting each of the consumed media types.
+func (c *swaggerConverter) requestBody(param *yaml.Node, consumes []string) *yaml.Node {
+	out := mappingNode()
+	appendPair(out, "description", mappingValue(param, "description"))
+	content := mappingNode()
+	for _, mediaType := range consumes {
+		media := mappingNode()
+		appendPair(media, "schema", mappingValue(param, "schema"))
+		appendPair(content, mediaType, media)
+	}
+	appendPair(out, "content", content)
+	appendPair(out, "required", mappingValue(param, "required"))
+	for idx := 0; idx+1 < len(param.Content); idx += 2 {
+		if key := param.Content[idx].Value; strings.HasPrefix(key, "x-") {
+			appendPair(out, key, param.Content[idx+1])
+		}
+	}
+	return out
+}
+
+// formRequestBody builds the request body of an operation from its form parameters.
+func (c *swaggerConverter) formRequestBody(where string, properties, required *yaml.Node, consumes []string, multipart bool) *yaml.Node {
+	mediaType := "application/x-www-form-urlencoded"
+	if multipart {
+		mediaType = "multipart/form-data"
+	}
+	if !slices.Contains(consumes, mediaType) {
+		c.warnf("%s: form parameters are sent as %s, which the operation does not declare to consume", where, mediaType)
+	}
+	schema := mappingNode()
+	appendPair(schema, "type", stringNode("object"))
+	appendPair(schema, "properties", properties)
+	if len(required.Content) > 0 {
+		appendPair(schema, "required", required)
+	}
+	media := mappingNode()
+	appendPair(media, "schema", schema)
+	content := mappingNode()
+	appendPair(content, mediaType, media)
+	out := mappingNode()
+	appendPair(out, "content", content)
+	if len(required.Content) > 0 {
+		appendPair(out, "required", boolNode(true))
+	}
+	return out
+}
+
+// parameter converts a non-body parameter, moving its type constraints into its schema.
+func (c *swaggerConverter) parameter(where string, param *yaml.Node) *yaml.Node {
+	if ref := mappingValue(param, "$ref"); ref != nil {
+		return param
+	}
+	out := mappingNode()
+	for idx := 0; idx+1 < len(param.Content); idx += 2 {
+		key, value := param.Content[idx].Value, param.Content[idx+1]
+		switch {
+		case key == "name", key == "in", key == "description", key == "required", key == "allowEmptyValue",
+			strings.HasPrefix(key, "x-"):
+			appendPair(out, key, value)
+		}
+	}
+	in := scalarValue(mappingValue(param, "in"))
+	if scalarValue(mappingValue(param, "type")) == "array" {
+		c.collectionFormat(where, param, in, out)
+	}
+	appendPair(out, "schema", c.parameterSchema(where, param))
+	return out
+}
+
+// collectionFormat converts the collection format of an array parameter into its OpenAPI 3 style.
+func (c *swaggerConverter) collectionFormat(where string, param *yaml.Node, in string, out *yaml.Node) {
+	format := scalarValue(mappingValue(param, "collectionFormat"))
+	name := scalarValue(mappingValue(param, "name"))
+	if in == "path" || in == "header" {
+		if format != "" && format != "csv" {
+			c.warnf("%s: %s collection format of %s parameter %s is not supported, converted to csv", where, format, in, name)
+		}
+		appendPair(out, "style", stringNode("simple"))
+		appendPair(out, "explode", boolNode(false))
+		return
+	}
+	style, explode := "form", false
+	switch format {
+	case "", "csv":
+	case "ssv":
+		style = "spaceDelimited"
+	case "pipes":
+		style = "pipeDelimited"
+	case "multi":
+		explode = true
+	default:
+		c.warnf("%s: %s collection format of parameter %s is not supported, converted to csv", where, format, name)
+	}
+	appendPair(out, "style", stringNode(style))
+	appendPair(out, "explode", boolNode(explode))
+}
+
+// parameterSchema builds the schema of a non-body parameter, header or items object from its type constraints.
+func (c *swaggerConverter) parameterSchema(where string, param *yaml.Node) *yaml.Node {
+	schema := mappingNode()
+	for idx := 0; idx+1 < len(param.Content); idx += 2 {
+		key, value := param.Content[idx].Value, param.Content[idx+1]
+		switch {
+		case key == "items":
+			if format := scalarValue(mappingValue(value, "collectionFormat")); format != "" && format != "csv" {
+				c.warnf("%s: nested %s collection format is not supported, converted to csv", where, format)
+			}
+			appendPair(schema, key, c.parameterSchema(where, value))
+		case slices.Contains(swaggerSchemaKeys, key):
+			appendPair(schema, key, value)
+		}
+	}
+	return schema
+}
+
+// response converts a response, declaring its schema for each of the produced media types.
+func (c *swaggerConverter) response(where string, response *yaml.Node, produces []string) *yaml.Node {
+	if ref := mappingValue(response, "$ref"); ref != nil {
+		return response
+	}
+	out := mappingNode()
+	description := mappingValue(response, "description")
+	if description == nil {
+		description = stringNode("")
+	}
+	appendPair(out, "description", description)
+	if headers := mappingValue(response, "headers"); headers != nil {
+		converted := mappingNode()
+		for idx := 0; idx+1 < len(headers.Content); idx += 2 {
+			name, header := headers.Content[idx].Value, headers.Content[idx+1]
+			convertedHeader := mappingNode()
+			appendPair(convertedHeader, "description", mappingValue(header, "description"))
+			appendPair(convertedHeader, "schema", c.parameterSchema(where, header))
+			appendPair(converted, name, convertedHeader)
+		}
+		appendPair(out, "headers", converted)
+	}
+	examples := mappingValue(response, "examples")
+	if schema := mappingValue(response, "schema"); schema != nil {
+		content := mappingNode()
+		for _, mediaType := range produces {
+			media := mappingNode()
+			appendPair(media, "schema", schema)
+			appendPair(media, "example", mappingValue(examples, mediaType))
+			appendPair(content, mediaType, media)
+		}
+		appendPair(out, "content", content)
+	}
+	for idx := 0; examples != nil && idx+1 < len(examples.Content); idx += 2 {
+		if mediaType := examples.Content[idx].Value; !slices.Contains(produces, mediaType) || mappingValue(response, "schema") == nil {
+			c.warnf("%s: example for %s has no matching response content and was dropped", where, mediaType)
+		}
+	}
+	for idx := 0; idx+1 < len(response.Content); idx += 2 {
+		if key := response.Content[idx].Value; strings.HasPrefix(key, "x-") {
+			appendPair(out, key, response.Content[idx+1])
+		}
+	}
+	return out
+}
+
+// securitySchemes converts the security definitions of the document.
+func (c *swaggerConverter) securitySchemes() *yaml.Node {
+	definitions := mappingValue(c.root, "securityDefinitions")
+	if definitions == nil {
+		return nil
+	}
+	out := mappingNode()
+	for idx := 0; idx+1 < len(definitions.Content); idx += 2 {
+		name, definition := definitions.Content[idx].Value, definitions.Content[idx+1]
+		scheme := mappingNode()
+		switch kind := scalarValue(mappingValue(definition, "type")); kind {
+		case "basic":
+			appendPair(scheme, "type", stringNode("http"))
+			appendPair(scheme, "scheme", stringNode("basic"))
+		case "apiKey":
+			appendPair(scheme, "type", stringNode("apiKey"))
+			appendPair(scheme, "name", mappingValue(definition, "name"))
+			appendPair(scheme, "in", mappingValue(definition, "in"))
+		case "oauth2":
+			appendPair(scheme, "type", stringNode("oauth2"))
+			flowName := map[string]string{
+				"implicit":    "implicit",
+				"password":    "password",
+				"application": "clientCredentials",
+				"accessCode":  "authorizationCode",
+			}[scalarValue(mappingValue(definition, "flow"))]
+			if flowName == "" {
+				c.warnf("securityDefinitions.%s: unknown oauth2 flow, the scheme was dropped", name)
+				continue
+			}
+			flow := mappingNode()
+			appendPair(flow, "authorizationUrl", mappingValue(definition, "authorizationUrl"))
+			appendPair(flow, "tokenUrl", mappingValue(definition, "tokenUrl"))
+			scopes := mappingValue(definition, "scopes")
+			if scopes == nil {
+				scopes = mappingNode()
+			}
+			appendPair(flow, "scopes", scopes)
+			flows := mappingNode()
+			appendPair(flows, flowName, flow)
+			appendPair(scheme, "flows", flows)
+		default:
+			c.warnf("securityDefinitions.%s: unsupported security type %s, the scheme was dropped", name, kind)
+			continue
+		}
+		appendPair(scheme, "description", mappingValue(definition, "description"))
+		appendPair(out, name, scheme)
+	}
+	return out
+}
+
+// rewriteSwaggerNodes walks the schemas, parameters, request bodies and responses of the converted document, updating
+// their references along with the Swagger 2.0 specific schema keywords. Examples, default values and extensions hold
+// user data, they are left untouched.
+func rewriteSwaggerNodes(document *yaml.Node) {
+	for _, item := range mappingValues(mappingValue(document, "paths")) {
+		for _, param := range sequenceValues(mappingValue(item, "parameters")) {
+			rewriteSwaggerParameter(param)
+		}
+		for _, method := range swaggerOperationMethods {
+			rewriteSwaggerOperation(mappingValue(item, method))
+		}
+	}
+	components := mappingValue(document, "components")
+	for _, schema := range mappingValues(mappingValue(components, "schemas")) {
+		rewriteSwaggerSchema(schema)
+	}
+	for _, param := range mappingValues(mappingValue(components, "parameters")) {
+		rewriteSwaggerParameter(param)
+	}
+	for _, body := range mappingValues(mappingValue(components, "requestBodies")) {
+		rewriteSwaggerContent(body)
+	}
+	for _, response := range mappingValues(mappingValue(components, "responses")) {
+		rewriteSwaggerResponse(response)
+	}
+}
+
+func rewriteSwaggerOperation(op *yaml.Node) {
+	if op == nil {
+		return
+	}
+	for _, param := range sequenceValues(mappingValue(op, "parameters")) {
+		rewriteSwaggerParameter(param)
+	}
+	rewriteSwaggerContent(mappingValue(op, "requestBody"))
+	responses := mappingValue(op, "responses")
+	for idx := 0; responses != nil && idx+1 < len(responses.Content); idx += 2 {
+		if !strings.HasPrefix(responses.Content[idx].Value, "x-") {
+			rewriteSwaggerResponse(responses.Content[idx+1])
+		}
+	}
+}
+
+func rewriteSwaggerParameter(param *yaml.Node) {
+	rewriteSwaggerRef(param)
+	rewriteSwaggerSchema(mappingValue(param, "schema"))
+}
+
+func rewriteSwaggerResponse(response *yaml.Node) {
+	rewriteSwaggerContent(response)
+	for _, header := range mappingValues(mappingValue(response, "headers")) {
+		rewriteSwaggerSchema(mappingValue(header, "schema"))
+	}
+}
+
+// rewriteSwaggerContent rewrites the schemas of the media types of a request body or response.
+func rewriteSwaggerContent(node *yaml.Node) {
+	rewriteSwaggerRef(node)
+	for _, media := range mappingValues(mappingValue(node, "content")) {
+		rewriteSwaggerSchema(mappingValue(media, "schema"))
+	}
+}
+
+// rewriteSwaggerSchema rewrites the Swagger 2.0 specific keywords of a schema, then the ones of its sub-schemas.
+func rewriteSwaggerSchema(schema *yaml.Node) {
+	if schema == nil || schema.Kind != yaml.MappingNode {
+		return
+	}
+	rewriteSwaggerRef(schema)
+	for idx := 0; idx+1 < len(schema.Content); idx += 2 {
+		key, value := schema.Content[idx], schema.Content[idx+1]
+		switch {
+		case key.Value == "x-nullable":
+			key.Value = "nullable"
+		case key.Value == "discriminator" && value.Kind == yaml.ScalarNode:
+			discriminator := mappingNode()
+			appendPair(discriminator, "propertyName", stringNode(value.Value))
+			schema.Content[idx+1] = discriminator
+		case key.Value == "type" && value.Kind == yaml.ScalarNode && value.Value == "file":
+			value.Value = "string"
+			if mappingValue(schema, "format") == nil {
+				schema.Content = append(schema.Content, stringNode("format"), stringNode("binary"))
+			}
+		}
+	}
+	for _, property := range mappingValues(mappingValue(schema, "properties")) {
+		rewriteSwaggerSchema(property)
+	}
+	rewriteSwaggerSchema(mappingValue(schema, "additionalProperties"))
+	rewriteSwaggerSchema(mappingValue(schema, "not"))
+	items := mappingValue(schema, "items")
+	rewriteSwaggerSchema(items)
+	for _, keyword := range slices.Of("allOf", "anyOf", "oneOf") {
+		for _, member := range sequenceValues(mappingValue(schema, keyword)) {
+			rewriteSwaggerSchema(member)
+		}
+	}
+	for _, item := range sequenceValues(items) {
+		rewriteSwaggerSchema(item)
+	}
+}
+
+// rewriteSwaggerRef points the reference of the node, if any, to the OpenAPI 3 location of its target.
+func rewriteSwaggerRef(node *yaml.Node) {
+	ref := mappingValue(node, "$ref")
+	if ref == nil || ref.Kind != yaml.ScalarNode {
+		return
+	}
+	for prefix, replacement := range swaggerRefPrefixes {
+		if name, found := strings.CutPrefix(ref.Value, prefix); found {
+			ref.Value = replacement + name
+		}
+	}
+}
+
+func mappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func sequenceNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func boolNode(value bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(value)}
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil if it has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// appendPair adds a key to a mapping node, unless the value is nil.
+func appendPair(mapping *yaml.Node, key string, value *yaml.Node) {
+	if value == nil {
+		return
+	}
+	mapping.Content = append(mapping.Content, stringNode(key), value)
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// mappingValues returns the values of a mapping node, in order.
+func mappingValues(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	values := make([]*yaml.Node, 0, len(node.Content)/2)
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		values = append(values, node.Content[idx+1])
+	}
+	return values
+}
+
+func sequenceValues(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func stringValues(node *yaml.Node) []string {
+	return slices.Map(sequenceValues(node), scalarValue)
+}
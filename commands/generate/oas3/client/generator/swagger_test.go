@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSwaggerGolden(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{name: "swagger", spec: "swagger"},
+	})
+}
+
+func TestSwaggerConversionOnlyRewritesSchemas(t *testing.T) {
+	spec := `
+swagger: "2.0"
+info: {title: data, version: "1"}
+x-meta: {$ref: '#/definitions/Kept', x-nullable: true}
+paths:
+  /things:
+    post:
+      parameters:
+        - {name: body, in: body, schema: {$ref: '#/definitions/Thing'}}
+      responses:
+        '200':
+          description: ok
+          schema: {type: array, items: {allOf: [{$ref: '#/definitions/Thing'}, {properties: {data: {type: file}}}]}}
+          examples:
+            application/json: {$ref: '#/definitions/Kept', discriminator: kept, type: file}
+definitions:
+  Thing:
+    type: object
+    discriminator: kind
+    x-payload: {discriminator: kept, x-nullable: true}
+    example: {$ref: '#/definitions/Kept', x-nullable: true}
+    properties:
+      kind: {type: string}
+      note: {type: string, x-nullable: true, default: {x-nullable: true}}
+      meta:
+        type: object
+        additionalProperties: {type: string, x-nullable: true}
+`
+	converted, _, err := convertSwagger([]byte(spec))
+	require.NoError(t, err)
+	var document map[string]any
+	require.NoError(t, yaml.Unmarshal(converted, &document))
+	at := func(path ...any) any {
+		var node any = document
+		for _, key := range path {
+			switch key := key.(type) {
+			case string:
+				node = node.(map[string]any)[key]
+			case int:
+				node = node.([]any)[key]
+			}
+		}
+		return node
+	}
+
+	thing := []any{"components", "schemas", "Thing"}
+	require.Equal(t, map[string]any{"propertyName": "kind"}, at(append(thing, "discriminator")...))
+	require.Equal(t, true, at(append(thing, "properties", "note", "nullable")...))
+	require.Equal(t, true, at(append(thing, "properties", "meta", "additionalProperties", "nullable")...))
+	require.Equal(t, "#/components/schemas/Thing", at("paths", "/things", "post", "requestBody", "content", "application/json", "schema", "$ref"))
+	response := []any{"paths", "/things", "post", "responses", "200", "content", "application/json"}
+	allOf := append(response, "schema", "items", "allOf")
+	require.Equal(t, "#/components/schemas/Thing", at(append(allOf, 0, "$ref")...))
+	require.Equal(t, map[string]any{"type": "string", "format": "binary"}, at(append(allOf, 1, "properties", "data")...))
+
+	require.Equal(t, map[string]any{"$ref": "#/definitions/Kept", "x-nullable": true}, at("x-meta"))
+	require.Equal(t, map[string]any{"discriminator": "kept", "x-nullable": true}, at(append(thing, "x-payload")...))
+	require.Equal(t, map[string]any{"$ref": "#/definitions/Kept", "x-nullable": true}, at(append(thing, "example")...))
+	require.Equal(t, map[string]any{"x-nullable": true}, at(append(thing, "properties", "note", "default")...))
+	require.Equal(t, map[string]any{"$ref": "#/definitions/Kept", "discriminator": "kept", "type": "file"}, at(append(response, "example")...))
+}
@@ -0,0 +1,59 @@
+package behaviour
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/sdk"
+
+	client "example.com/petstore"
+	"example.com/petstore/dtos"
+)
+
+// petName is code under test, depending on the operations of the client rather than on the client itself.
+func petName(ctx context.Context, c client.ClientInterface, id string) (string, error) {
+	pet, err := c.GetPet(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return pet.Name, nil
+}
+
+func TestMockClientReturnsTheProgrammedResponses(t *testing.T) {
+	mock := &client.MockClient{
+		GetPetFunc: func(_ context.Context, petID string, _ ...opt.Option[sdk.Request]) (*dtos.Pet, error) {
+			return &dtos.Pet{Name: "pet " + petID}, nil
+		},
+	}
+	name, err := petName(context.Background(), mock, "42")
+	require.NoError(t, err)
+	require.Equal(t, "pet 42", name)
+}
+
+func TestMockClientRecordsTheCalls(t *testing.T) {
+	mock := &client.MockClient{
+		GetPetFunc: func(context.Context, string, ...opt.Option[sdk.Request]) (*dtos.Pet, error) {
+			return &dtos.Pet{}, nil
+		},
+	}
+	ctx := context.Background()
+	_, _ = petName(ctx, mock, "1")
+	_, _ = petName(ctx, mock, "2")
+	_, _ = mock.ListPets(ctx, nil)
+
+	require.Len(t, mock.Calls(), 3)
+	calls := mock.CallsTo("GetPet")
+	require.Len(t, calls, 2)
+	require.Equal(t, "2", calls[1].Args[1])
+
+	mock.Reset()
+	require.Empty(t, mock.Calls())
+}
+
+func TestMockClientFailsWithoutProgrammedResponse(t *testing.T) {
+	_, err := (&client.MockClient{}).ListPets(context.Background(), nil)
+	require.ErrorContains(t, err, "ListPetsFunc")
+}
@@ -0,0 +1,122 @@
+package behaviour
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/composition"
+	"example.com/composition/dtos"
+)
+
+func TestDiscriminatedUnionsDecodeTheMappedVariant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"1","kind":"cat","meows":true},{"id":"2","kind":"Dog","barks":false}]`))
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+
+	animals, err := c.ListAnimals(context.Background())
+	require.NoError(t, err)
+	require.Len(t, *animals, 2)
+	cat, dog := (*animals)[0], (*animals)[1]
+	require.Nil(t, cat.Dog)
+	require.Equal(t, "1", cat.Cat.ID)
+	require.True(t, *cat.Cat.Meows)
+	require.Nil(t, dog.Cat)
+	require.Equal(t, "2", dog.Dog.ID)
+	require.False(t, *dog.Dog.Barks)
+}
+
+func TestDiscriminatedUnionsRejectUnknownValues(t *testing.T) {
+	var animal dtos.Animal
+	err := json.Unmarshal([]byte(`{"id":"1","kind":"bird"}`), &animal)
+	require.ErrorContains(t, err, `unknown Animal kind "bird"`)
+}
+
+func TestDiscriminatedUnionsMarshalTheirVariant(t *testing.T) {
+	data, err := json.Marshal(dtos.Animal{Cat: &dtos.Cat{ID: "1", Kind: "cat"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"1","kind":"cat"}`, string(data))
+}
+
+func TestUnionsWithoutDiscriminatorDecodeByTrial(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected dtos.Value
+	}{
+		{name: "string", data: `"abc"`, expected: dtos.Value{String: ptr("abc")}},
+		{name: "integer", data: `42`, expected: dtos.Value{Integer: ptr(int64(42))}},
+		{name: "object", data: `{"id":"1"}`, expected: dtos.Value{Base: &dtos.Base{ID: "1"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var value dtos.Value
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &value))
+			require.Equal(t, tc.expected, value)
+			data, err := json.Marshal(value)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.data, string(data))
+		})
+	}
+}
+
+func TestExclusiveUnionsDecodeTheMatchingVariant(t *testing.T) {
+	var shape dtos.Shape
+	require.NoError(t, json.Unmarshal([]byte(`{"side":2}`), &shape))
+	require.Nil(t, shape.Circle)
+	require.Equal(t, float64(2), shape.Square.Side)
+
+	err := json.Unmarshal([]byte(`{"edges":3}`), &shape)
+	require.ErrorContains(t, err, "data does not match any variant of Shape")
+}
+
+func TestNullUnionsAreEmpty(t *testing.T) {
+	var holder dtos.Holder
+	require.NoError(t, json.Unmarshal([]byte(`{"value":null}`), &holder))
+	require.Nil(t, holder.Value)
+	data, err := json.Marshal(dtos.Value{})
+	require.NoError(t, err)
+	require.Equal(t, "null", string(data))
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestInclusiveUnionsMergeTheirVariants(t *testing.T) {
+	tagged := dtos.Tagged{Named: &dtos.Named{Name: ptr("rex")}, Aged: &dtos.Aged{Age: ptr(int64(3))}}
+	data, err := json.Marshal(tagged)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"rex","age":3}`, string(data))
+
+	var decoded dtos.Tagged
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, tagged, decoded)
+}
+
+func TestInclusiveUnionsOnlyMergeObjects(t *testing.T) {
+	_, err := json.Marshal(dtos.Value{String: ptr("abc"), Integer: ptr(int64(42))})
+	require.ErrorContains(t, err, "cannot merge the variants set of Value")
+}
+
+func TestExclusiveUnionsRejectSeveralVariants(t *testing.T) {
+	var ambiguous dtos.Ambiguous
+	err := json.Unmarshal([]byte(`{"name":"rex"}`), &ambiguous)
+	require.ErrorContains(t, err, "data matches 2 variants of Ambiguous")
+	require.Equal(t, dtos.Ambiguous{}, ambiguous)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"rex","label":"dog"}`), &ambiguous))
+	require.Nil(t, ambiguous.Named)
+	require.Equal(t, "dog", *ambiguous.Labelled.Label)
+
+	_, err = json.Marshal(dtos.Shape{Circle: &dtos.Circle{Radius: 1}, Square: &dtos.Square{Side: 1}})
+	require.ErrorContains(t, err, "2 variants of Shape are set")
+}
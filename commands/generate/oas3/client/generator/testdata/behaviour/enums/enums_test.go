@@ -0,0 +1,69 @@
+package behaviour
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/enums"
+	"example.com/enums/dtos"
+)
+
+// listThings calls ListThings against a server replying with the given JSON body.
+func listThings(t *testing.T, body string) ([]dtos.Thing, error) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+	things, err := c.ListThings(context.Background())
+	if things == nil {
+		return nil, err
+	}
+	return *things, err
+}
+
+func TestKnownEnumValuesAreDecoded(t *testing.T) {
+	things, err := listThings(t, `[{"status":"in-active","priority":3},{"status":""}]`)
+	require.NoError(t, err)
+	require.Equal(t, []dtos.Thing{
+		{Status: ptr(dtos.StatusInActive), Priority: ptr(dtos.PriorityHigh)},
+		{Status: ptr(dtos.StatusEmpty)},
+	}, things)
+}
+
+// TestLenientEnums is not parallel, as SetLenientEnums is process wide.
+func TestLenientEnums(t *testing.T) {
+	_, err := listThings(t, `[{"status":"archived"}]`)
+	require.ErrorContains(t, err, "could not deserialize response")
+	var status dtos.Status
+	require.EqualError(t, json.Unmarshal([]byte(`"archived"`), &status), "invalid Status value archived")
+	var priority dtos.Priority
+	require.EqualError(t, json.Unmarshal([]byte(`4`), &priority), "invalid Priority value 4")
+
+	dtos.SetLenientEnums(true)
+	defer dtos.SetLenientEnums(false)
+	things, err := listThings(t, `[{"status":"archived","priority":4}]`)
+	require.NoError(t, err)
+	require.Equal(t, dtos.Status("archived"), *things[0].Status)
+	require.False(t, things[0].Status.IsValid())
+	require.Equal(t, dtos.Priority(4), *things[0].Priority)
+}
+
+func TestEnumsListTheirValues(t *testing.T) {
+	require.Equal(t, []dtos.Priority{dtos.PriorityLow, dtos.PriorityMedium, dtos.PriorityHigh}, dtos.Priority(0).Values())
+	require.True(t, dtos.Status2Fa.IsValid())
+	data, err := json.Marshal(dtos.Thing{Status: ptr(dtos.Status2Fa)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status":"2fa"}`, string(data))
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
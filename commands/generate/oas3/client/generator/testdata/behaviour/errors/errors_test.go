@@ -0,0 +1,59 @@
+package behaviour
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/errors"
+)
+
+// getItem calls GetItem against a server replying with the given status code and JSON body.
+func getItem(t *testing.T, statusCode int, body string) error {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+	_, err = c.GetItem(context.Background(), "42")
+	return err
+}
+
+func TestExactStatusCodesAreDecodedIntoTheirTypedError(t *testing.T) {
+	err := getItem(t, http.StatusNotFound, `{"missing":"42"}`)
+	var notFound *client.GetItemNotFoundError
+	require.True(t, errors.As(err, &notFound), "%v is not a GetItemNotFoundError", err)
+	require.Equal(t, http.StatusNotFound, notFound.StatusCode)
+	require.Equal(t, "42", *notFound.Body.Missing)
+}
+
+func TestStatusCodeRangesAreDecodedIntoTheirTypedError(t *testing.T) {
+	err := getItem(t, http.StatusConflict, `{"title":"conflict"}`)
+	var clientErr *client.GetItem4XXError
+	require.True(t, errors.As(err, &clientErr), "%v is not a GetItem4XXError", err)
+	require.Equal(t, http.StatusConflict, clientErr.StatusCode)
+	require.Equal(t, "conflict", *clientErr.Body.Title)
+}
+
+func TestUndocumentedStatusCodesAreDecodedIntoTheDefaultError(t *testing.T) {
+	err := getItem(t, http.StatusInternalServerError, `{"title":"boom"}`)
+	var defaultErr *client.GetItemDefaultError
+	require.True(t, errors.As(err, &defaultErr), "%v is not a GetItemDefaultError", err)
+	require.Equal(t, http.StatusInternalServerError, defaultErr.StatusCode)
+	require.Equal(t, "boom", *defaultErr.Body.Title)
+}
+
+func TestUndecodableErrorsKeepTheResponseError(t *testing.T) {
+	err := getItem(t, http.StatusServiceUnavailable, `not json`)
+	var responseErr *client.ResponseError
+	require.True(t, errors.As(err, &responseErr), "%v is not a ResponseError", err)
+	require.Equal(t, http.StatusServiceUnavailable, responseErr.StatusCode)
+	require.Equal(t, "not json", string(responseErr.Body))
+}
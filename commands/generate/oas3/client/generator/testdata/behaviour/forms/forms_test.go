@@ -0,0 +1,56 @@
+package behaviour
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/forms"
+	"example.com/forms/dtos"
+)
+
+// formServer records the content type and the decoded form of the last request it received.
+func formServer(t *testing.T, contentType *string, form *url.Values) *client.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		*contentType = r.Header.Get("Content-Type")
+		*form, err = url.ParseQuery(string(body))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"a":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func TestFormBodiesAreEncodedByWireNames(t *testing.T) {
+	var contentType string
+	var form url.Values
+	c := formServer(t, &contentType, &form)
+	ttl := int64(5)
+
+	response, err := c.Login(context.Background(), dtos.Login{UserName: "bob", TTL: &ttl})
+	require.NoError(t, err)
+	require.Equal(t, "ok", *response.A)
+	require.Equal(t, "application/x-www-form-urlencoded", contentType)
+	require.Equal(t, url.Values{"user_name": {"bob"}, "ttl": {"5"}}, form)
+}
+
+func TestFormBodiesOmitUnsetOptionalProperties(t *testing.T) {
+	var contentType string
+	var form url.Values
+	c := formServer(t, &contentType, &form)
+	nickname := ""
+
+	_, err := c.AddNote(context.Background(), dtos.AddNoteRequest{Text: "hi", Nickname: &nickname})
+	require.NoError(t, err)
+	require.Equal(t, url.Values{"text": {"hi"}, "nickname": {""}}, form)
+}
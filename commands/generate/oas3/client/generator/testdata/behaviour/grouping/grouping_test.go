@@ -0,0 +1,66 @@
+package behaviour
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/sdk"
+
+	client "example.com/grouping_tag"
+	"example.com/grouping_tag/dtos"
+)
+
+func TestSubClientsShareTheClient(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user, err := c.Users.GetUser(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "1", *user.ID)
+	_, err = c.ClientAPI.GetClientInfo(ctx)
+	require.NoError(t, err)
+	_, err = c.Health(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/v1/users/1", "/api/v1/client", "/api/v1/health"}, paths)
+}
+
+// userID is code under test, depending on the operations of the client rather than on the client itself.
+func userID(ctx context.Context, c client.ClientInterface, id string) (string, error) {
+	user, err := c.UsersClient().GetUser(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return *user.ID, nil
+}
+
+func TestSubClientsAreMocked(t *testing.T) {
+	for name, mock := range map[string]*client.MockClient{
+		"allocated by NewMockClient": client.NewMockClient(),
+		"allocated by the accessor":  {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_ = mock.UsersClient()
+			mock.Users.GetUserFunc = func(_ context.Context, id string, _ ...opt.Option[sdk.Request]) (*dtos.User, error) {
+				return &dtos.User{ID: &id}, nil
+			}
+			id, err := userID(context.Background(), mock, "7")
+			require.NoError(t, err)
+			require.Equal(t, "7", id)
+			require.Len(t, mock.Users.CallsTo("GetUser"), 1)
+			require.Empty(t, mock.Calls(), "the calls are recorded by the mock of the sub-client")
+		})
+	}
+}
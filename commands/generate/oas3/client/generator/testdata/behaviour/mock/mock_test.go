@@ -0,0 +1,65 @@
+package behaviour
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"example.com/examples_mock/dtos"
+	"example.com/examples_mock/mock"
+)
+
+// get performs a request against the server, returning the status code and the body of the response.
+func get(t *testing.T, method, url string) (int, string) {
+	request, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return response.StatusCode, string(body)
+}
+
+func TestResponsesAreSynthesizedFromTheSchema(t *testing.T) {
+	server, _ := mock.NewServer()
+	defer server.Close()
+	status, body := get(t, http.MethodGet, server.URL+"/nodes/root")
+	require.Equal(t, http.StatusOK, status)
+	var node map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &node))
+	require.Equal(t, "root", node["name"], "the example of the property is used")
+	require.Equal(t, "2024-01-01", node["created"], "the values are synthesized from the format")
+	require.NotContains(t, body, "secret", "write only properties are not part of responses")
+}
+
+func TestResponsesUseTheExamplesOfTheSpec(t *testing.T) {
+	server, _ := mock.NewServer()
+	defer server.Close()
+	status, body := get(t, http.MethodGet, server.URL+"/nodes/root.json")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"name":"first","created":"2024-05-01"}`, body)
+
+	status, _ = get(t, http.MethodHead, server.URL+"/ping")
+	require.Equal(t, http.StatusNoContent, status)
+}
+
+func TestOperationsCanBeOverridden(t *testing.T) {
+	server, handler := mock.NewServer()
+	defer server.Close()
+	handler.OnGetNode(func(r *http.Request) (int, dtos.Node) {
+		return http.StatusTeapot, dtos.Node{Name: r.PathValue("node_id")}
+	})
+	status, body := get(t, http.MethodGet, server.URL+"/nodes/leaf")
+	require.Equal(t, http.StatusTeapot, status)
+	require.JSONEq(t, `{"name":"leaf"}`, body)
+
+	handler.Reset()
+	status, _ = get(t, http.MethodGet, server.URL+"/nodes/leaf")
+	require.Equal(t, http.StatusOK, status)
+
+	require.Panics(t, func() { handler.Override("Unknown", nil) })
+}
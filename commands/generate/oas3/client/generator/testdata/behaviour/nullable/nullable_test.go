@@ -0,0 +1,80 @@
+package behaviour
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/nullable"
+	"example.com/nullable/dtos"
+)
+
+// echo sends the patch through UpdatePatch to a server replying with the body it received, which it returns along
+// with the decoded response.
+func echo(t *testing.T, patch dtos.Patch) (string, dtos.Patch) {
+	var sent []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		sent, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(sent)
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+	response, err := c.UpdatePatch(context.Background(), "1", patch)
+	require.NoError(t, err)
+	return string(sent), *response
+}
+
+func TestAbsentValuesAreOmitted(t *testing.T) {
+	sent, received := echo(t, dtos.Patch{ID: "1", Owner: dtos.NewNullable("bob")})
+	require.JSONEq(t, `{"id":"1","owner":"bob"}`, sent)
+
+	_, set := received.Name.Get()
+	require.False(t, set)
+	require.True(t, received.Name.IsZero())
+	require.True(t, received.Note.IsZero())
+	require.False(t, received.Note.IsNull())
+	owner, valid := received.Owner.Get()
+	require.True(t, valid)
+	require.Equal(t, "bob", owner)
+}
+
+func TestNullValuesAreSentAsNull(t *testing.T) {
+	sent, received := echo(t, dtos.Patch{ID: "1", Owner: dtos.Null[string](), Note: dtos.Null[string]()})
+	require.JSONEq(t, `{"id":"1","owner":null,"note":null}`, sent)
+
+	for _, value := range []dtos.Nullable[string]{received.Owner, received.Note} {
+		_, valid := value.Get()
+		require.False(t, valid)
+		require.True(t, value.IsNull())
+		require.False(t, value.IsZero())
+	}
+}
+
+func TestSetValuesAreSent(t *testing.T) {
+	patch := dtos.Patch{
+		ID:      "1",
+		Owner:   dtos.NewNullable("bob"),
+		Note:    dtos.NewNullable(""),
+		Name:    dtos.NewOptional("rex"),
+		Tags:    dtos.NewOptional([]string{}),
+		Address: dtos.NewOptional(dtos.PatchAddress{City: dtos.NewOptional("Paris")}),
+	}
+	sent, received := echo(t, patch)
+	require.JSONEq(t, `{"id":"1","owner":"bob","note":"","name":"rex","tags":[],"address":{"city":"Paris"}}`, sent)
+	require.Equal(t, patch, received)
+}
+
+func TestUnsetRequiredNullableValuesAreNull(t *testing.T) {
+	data, err := json.Marshal(dtos.Patch{ID: "1"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"1","owner":null}`, string(data))
+}
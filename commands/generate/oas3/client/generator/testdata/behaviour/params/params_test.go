@@ -0,0 +1,80 @@
+package behaviour
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	client "example.com/params"
+)
+
+// recordedRequest holds the parts of a request the parameters are encoded into.
+type recordedRequest struct {
+	query  url.Values
+	header http.Header
+}
+
+func listEvents(t *testing.T, params *client.ListEventsParams) recordedRequest {
+	var recorded recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded = recordedRequest{query: r.URL.Query(), header: r.Header}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	c, err := client.NewClient(server.URL)
+	require.NoError(t, err)
+	_, err = c.ListEvents(context.Background(), params)
+	require.NoError(t, err)
+	return recorded
+}
+
+func TestExplodedArraysAreSentAsRepeatedPairs(t *testing.T) {
+	recorded := listEvents(t, &client.ListEventsParams{
+		Ids:        []int32{1, 2, 3},
+		Tags:       []string{"a", "b"},
+		Labels:     []string{"x", "y"},
+		XRequestID: "request",
+	})
+	require.Equal(t, []string{"1", "2", "3"}, recorded.query["ids"])
+	require.Equal(t, []string{"a,b"}, recorded.query["tags"])
+	require.Equal(t, []string{"x|y"}, recorded.query["labels"])
+}
+
+func TestUnsetParametersUseTheirDefault(t *testing.T) {
+	recorded := listEvents(t, &client.ListEventsParams{XRequestID: "request"})
+	require.Equal(t, url.Values{"limit": {"20"}}, recorded.query)
+	require.Equal(t, "request", recorded.header.Get("X-Request-Id"))
+	require.Empty(t, recorded.header.Get("Cookie"))
+}
+
+func TestHeaderAndCookieParameters(t *testing.T) {
+	session := "token"
+	recorded := listEvents(t, &client.ListEventsParams{XRequestID: "request", Session: &session})
+	require.Equal(t, "request", recorded.header.Get("X-Request-Id"))
+	require.Equal(t, "session=token", recorded.header.Get("Cookie"))
+}
+
+func TestDateTimeParameters(t *testing.T) {
+	after := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	recorded := listEvents(t, &client.ListEventsParams{XRequestID: "request", After: &after})
+	require.Equal(t, "2024-01-02T03:04:05Z", recorded.query.Get("after"))
+}
+
+func TestDateParametersAreSentWithoutTime(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	recorded := listEvents(t, &client.ListEventsParams{
+		XRequestID: "request",
+		Since:      &day,
+		Days:       []time.Time{day, day.AddDate(0, 0, 1)},
+		XDay:       &day,
+	})
+	require.Equal(t, "2024-01-02", recorded.query.Get("since"))
+	require.Equal(t, []string{"2024-01-02", "2024-01-03"}, recorded.query["days"])
+	require.Equal(t, "2024-01-02", recorded.header.Get("X-Day"))
+}
@@ -0,0 +1,106 @@
+package behaviour
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/sdk"
+
+	client "example.com/security"
+)
+
+// credentials records the credentials of the last request received by the server.
+type credentials struct {
+	apiKey        string
+	authorization string
+	queryKey      string
+}
+
+func securedClient(t *testing.T, opts ...opt.Option[sdk.Config]) (*client.Client, *credentials) {
+	received := &credentials{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*received = credentials{
+			apiKey:        r.Header.Get("X-API-Key"),
+			authorization: r.Header.Get("Authorization"),
+			queryKey:      r.URL.Query().Get("key"),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := client.NewClient(server.URL, opts...)
+	require.NoError(t, err)
+	return c, received
+}
+
+func TestCredentialsAreOnlySentToTheOperationsRequiringThem(t *testing.T) {
+	ctx := context.Background()
+	c, received := securedClient(t,
+		client.WithAPIKey("key"),
+		client.WithBearerToken("token"),
+		client.WithBasicAuth("user", "secret"),
+		client.WithQueryKeyAPIKey("query"),
+	)
+
+	_, err := c.GetPublic(ctx)
+	require.NoError(t, err)
+	require.Equal(t, credentials{}, *received)
+
+	_, err = c.GetKeyed(ctx)
+	require.NoError(t, err)
+	require.Equal(t, credentials{apiKey: "key"}, *received)
+
+	_, err = c.GetEither(ctx)
+	require.NoError(t, err)
+	require.Equal(t, credentials{authorization: "Bearer token"}, *received)
+
+	_, err = c.GetBasic(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Basic dXNlcjpzZWNyZXQ=", received.authorization)
+	require.Equal(t, "query", received.queryKey)
+}
+
+func TestTokenSourcesAreCalledForEachRequest(t *testing.T) {
+	var calls atomic.Int32
+	c, received := securedClient(t, client.WithAPIKeySource(client.TokenSourceFunc(func(context.Context) (string, error) {
+		calls.Add(1)
+		return "rotated", nil
+	})))
+	for range 2 {
+		_, err := c.GetKeyed(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, "rotated", received.apiKey)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestOAuth2ClientCredentialsAreExchangedForCachedTokens(t *testing.T) {
+	var exchanges atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "read", r.PostForm.Get("scope"))
+		clientID, clientSecret, _ := r.BasicAuth()
+		require.Equal(t, "id", clientID)
+		require.Equal(t, "secret", clientSecret)
+		exchanges.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	source := client.NewClientCredentialsTokenSource(tokenServer.URL, "id", "secret", "read")
+	c, received := securedClient(t, client.WithOAuth2TokenSource(source))
+	for range 2 {
+		_, err := c.GetEither(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, "Bearer access", received.authorization)
+	require.Equal(t, int32(1), exchanges.Load())
+}
@@ -0,0 +1,97 @@
+package behaviour
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/web/api"
+
+	"example.com/petstore_server/dtos"
+	"example.com/petstore_server/server"
+)
+
+var errNoSuchPet = errors.New("no such pet")
+
+// pets implements some of the operations of the PetsService, the other ones being left unimplemented.
+type pets struct {
+	server.UnimplementedPetsService
+	listed *server.ListPetsInput
+}
+
+func (p *pets) ListPets(_ context.Context, input *server.ListPetsInput) (*api.Response[[]dtos.Pet], error) {
+	p.listed = input
+	return api.Ok([]dtos.Pet{})
+}
+
+func (p *pets) GetPet(_ context.Context, input *server.GetPetInput) (*api.Response[dtos.Pet], error) {
+	if input.PetID == "missing" {
+		return nil, errNoSuchPet
+	}
+	return api.Ok(dtos.Pet{ID: uuid.Nil, Name: "pet " + input.PetID})
+}
+
+// mount mounts the handlers of every operation on a test API, converting errNoSuchPet into a 404 Not Found status.
+func mount(t *testing.T, service server.PetsService) humatest.TestAPI {
+	_, humaAPI := humatest.New(t)
+	config := api.Config{ErrorConverter: func(err error) error {
+		if errors.Is(err, errNoSuchPet) {
+			return huma.Error404NotFound(err.Error())
+		}
+		return err
+	}}
+	for _, handler := range []*api.Handler{
+		server.NewListPetsHandler(service),
+		server.NewCreatePetHandler(service),
+		server.NewGetPetHandler(service),
+		server.NewUpdatePetHandler(service),
+	} {
+		handler.Mount(humaAPI, config)
+	}
+	return humaAPI
+}
+
+func TestImplementedOperationsReceiveTheirParameters(t *testing.T) {
+	humaAPI := mount(t, &pets{})
+	response := humaAPI.Get("/pets/42")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.Contains(t, response.Body.String(), `"name":"pet 42"`)
+}
+
+func TestErrorsAreConverted(t *testing.T) {
+	humaAPI := mount(t, &pets{})
+	response := humaAPI.Get("/pets/missing")
+	require.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestUnimplementedOperationsReplyNotImplemented(t *testing.T) {
+	humaAPI := mount(t, &pets{})
+	response := humaAPI.Post("/pets", map[string]any{"name": "rex"})
+	require.Equal(t, http.StatusNotImplemented, response.Code, response.Body.String())
+}
+
+func TestParametersAreBoundWithTheirDefaults(t *testing.T) {
+	service := &pets{}
+	humaAPI := mount(t, service)
+	response := humaAPI.Get("/pets?tags=a,b", "X-Request-Id: 7")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.Equal(t, int32(20), service.listed.Limit)
+	require.Equal(t, []string{"a", "b"}, service.listed.Tags)
+	require.Equal(t, "7", service.listed.XRequestID)
+}
+
+func TestRequestsAreValidated(t *testing.T) {
+	humaAPI := mount(t, &pets{})
+	response := humaAPI.Get("/pets")
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code, "the X-Request-Id header is required")
+
+	response = humaAPI.Post("/pets", strings.NewReader(`{"tag":"dog"}`))
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code, "the name of the pet is required")
+}
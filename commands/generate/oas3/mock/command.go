@@ -0,0 +1,13 @@
+package mock
+
+import (
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client"
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
+	"github.com/kiwiworks/rodent/command"
+)
+
+func GenerateOpenAPIMock() *command.Command {
+	flags := generator.DefaultFlags()
+	flags.Target = generator.TargetMock
+	return client.GenerateCommand("generate.openapi.mock", "Generate an OAS3 fake server for tests", flags)
+}
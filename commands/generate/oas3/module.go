@@ -2,6 +2,8 @@ package oas3
 
 import (
 	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client"
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/mock"
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/server"
 	"github.com/kiwiworks/rodent/app"
 	"github.com/kiwiworks/rodent/command"
 )
@@ -11,6 +13,8 @@ func Module() app.Module {
 		command.Commands(
 			GenerateOpenapiCommandGroup,
 			client.GenerateOpenAPIClient,
+			server.GenerateOpenAPIServer,
+			mock.GenerateOpenAPIMock,
 		),
 	)
 }
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client"
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
+	"github.com/kiwiworks/rodent/command"
+)
+
+func GenerateOpenAPIServer() *command.Command {
+	flags := generator.DefaultFlags()
+	flags.Target = generator.TargetServer
+	return client.GenerateCommand("generate.openapi.server", "Generate OAS3 server stubs on the rodent web stack", flags)
+}
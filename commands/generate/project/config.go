@@ -0,0 +1,181 @@
+package project
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// DefaultConfigFile is the project file looked up in the working directory by `rodent-cli generate`.
+const DefaultConfigFile = "rodent.yaml"
+
+// Config is the content of a project file, listing the specs to generate code from.
+type Config struct {
+	Specs []Spec `yaml:"specs"`
+}
+
+// Spec describes the code generated from a single spec. Relative paths are resolved against the directory of the
+// project file.
+type Spec struct {
+	// Name identifies the spec, it defaults to its filename or url.
+	Name string `yaml:"name"`
+	// Filename is the path of the spec, exclusive with URL.
+	Filename string `yaml:"filename"`
+	// URL is the url the spec is downloaded from, exclusive with Filename.
+	URL string `yaml:"url"`
+	// Target is either `client`, `server` or `mock`, it defaults to `client`.
+	Target string `yaml:"target"`
+	// Output is the directory the code is generated into.
+	Output string `yaml:"output"`
+	// Module is the path of the generated module, it defaults to the sanitized title of the spec.
+	Module string `yaml:"module"`
+	// Package is the name of the generated package, it defaults to the target.
+	Package string `yaml:"package"`
+	// DTOsPackage is the name of the package holding the types of the schemas, it defaults to `dtos`.
+	DTOsPackage string `yaml:"dtosPackage"`
+	// GenerateModule generates a go.mod file along with the code, it defaults to true.
+	GenerateModule *bool `yaml:"generateModule"`
+	// GoVersion is the version of the go directive of the generated go.mod, it defaults to the local toolchain.
+	GoVersion string `yaml:"goVersion"`
+	// Offline generates a complete go.mod and go.sum from the local module cache, without any network access.
+	Offline bool `yaml:"offline"`
+	// OptionalStyle is either `pointer` or `nullable`.
+	OptionalStyle string `yaml:"optionalStyle"`
+	// Ordering is either `sorted` or `spec`.
+	Ordering string `yaml:"ordering"`
+	// Grouping is either `none`, `tag`, `path` or `x-group`.
+	Grouping string `yaml:"grouping"`
+	// AllowHosts lists the hosts remote references can be fetched from.
+	AllowHosts []string `yaml:"allowHosts"`
+	// Types maps schemas and formats to existing Go types, written as `import/path.Type`.
+	Types struct {
+		Schemas map[string]string `yaml:"schemas"`
+		Formats map[string]string `yaml:"formats"`
+	} `yaml:"types"`
+	// Include restricts the generated operations to the ones it matches.
+	Include generator.OperationFilter `yaml:"include"`
+	// Exclude skips the operations it matches.
+	Exclude generator.OperationFilter `yaml:"exclude"`
+	// Names renames the generated types, by schema name, and methods, by operation id.
+	Names struct {
+		Schemas    map[string]string `yaml:"schemas"`
+		Operations map[string]string `yaml:"operations"`
+	} `yaml:"names"`
+}
+
+// LoadConfig reads and validates the project file at filename.
+func LoadConfig(filename string) (*Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read project file %s", filename)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// typos would otherwise silently fall back to the defaults
+	decoder.KnownFields(true)
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse project file %s", filename)
+	}
+	if len(config.Specs) == 0 {
+		return nil, errors.Newf("project file %s does not list any spec", filename)
+	}
+	dir := filepath.Dir(filename)
+	names := make(map[string]bool, len(config.Specs))
+	for idx := range config.Specs {
+		spec := &config.Specs[idx]
+		if err := spec.validate(idx); err != nil {
+			return nil, errors.Wrapf(err, "invalid project file %s", filename)
+		}
+		if names[spec.Name] {
+			return nil, errors.Newf("invalid project file %s, spec %s is listed twice", filename, spec.Name)
+		}
+		names[spec.Name] = true
+		spec.Output = resolvePath(dir, spec.Output)
+		if spec.Filename != "" {
+			spec.Filename = resolvePath(dir, spec.Filename)
+		}
+	}
+	return &config, nil
+}
+
+// resolvePath resolves a path of the project file against its directory.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func (s *Spec) validate(idx int) error {
+	if s.Filename == "" && s.URL == "" || s.Filename != "" && s.URL != "" {
+		return errors.Newf("spec %d must have either a filename or a url", idx)
+	}
+	if s.Name == "" {
+		s.Name = s.Filename + s.URL
+	}
+	if s.Output == "" {
+		return errors.Newf("spec %s has no output directory", s.Name)
+	}
+	if s.URL != "" {
+		if _, err := url.Parse(s.URL); err != nil {
+			return errors.Wrapf(err, "spec %s has an invalid url", s.Name)
+		}
+	}
+	return nil
+}
+
+// URI returns the location of the spec.
+func (s *Spec) URI() (url.URL, error) {
+	if s.URL == "" {
+		return url.URL{Scheme: "file", Path: s.Filename}, nil
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return url.URL{}, errors.Wrapf(err, "invalid url %s", s.URL)
+	}
+	return *u, nil
+}
+
+// Flags returns the generator flags of the spec.
+func (s *Spec) Flags() generator.Flags {
+	flags := generator.DefaultFlags()
+	flags.OutputDir = s.Output
+	if s.Target != "" {
+		flags.Target = s.Target
+	}
+	if s.GenerateModule != nil {
+		flags.GenerateModule = *s.GenerateModule
+	}
+	if s.OptionalStyle != "" {
+		flags.OptionalStyle = s.OptionalStyle
+	}
+	if s.Ordering != "" {
+		flags.Ordering = s.Ordering
+	}
+	if s.Grouping != "" {
+		flags.Grouping = s.Grouping
+	}
+	if s.Package != "" {
+		flags.PackageName = s.Package
+	}
+	if s.DTOsPackage != "" {
+		flags.DTOsPackageName = s.DTOsPackage
+	}
+	flags.ModuleName = s.Module
+	flags.GoVersion = s.GoVersion
+	flags.Offline = s.Offline
+	flags.AllowedHosts = s.AllowHosts
+	flags.TypeMappings = s.Types.Schemas
+	flags.FormatMappings = s.Types.Formats
+	flags.TypeNames = s.Names.Schemas
+	flags.MethodNames = s.Names.Operations
+	flags.Include = s.Include
+	flags.Exclude = s.Exclude
+	return flags
+}
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
+)
+
+// writeConfig writes a project file with the given content into a temporary directory, and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), DefaultConfigFile)
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	return filename
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename := writeConfig(t, `
+specs:
+  - name: pets
+    filename: specs/petstore.yaml
+    output: ./gen/pets
+    target: mock
+    module: example.com/pets
+    package: fake
+    dtosPackage: models
+    generateModule: false
+    optionalStyle: nullable
+    ordering: spec
+    grouping: tag
+    allowHosts: [schemas.example.com]
+    types:
+      schemas: {Money: example.com/money.Amount}
+      formats: {decimal: example.com/money.Decimal}
+    names:
+      schemas: {Pet: Animal}
+      operations: {listPets: All}
+    include: {tags: [pets]}
+  - url: https://api.example.com/openapi.yaml
+    output: /abs/out
+`)
+	dir := filepath.Dir(filename)
+	config, err := LoadConfig(filename)
+	require.NoError(t, err)
+	require.Len(t, config.Specs, 2)
+
+	pets := config.Specs[0]
+	require.Equal(t, "pets", pets.Name)
+	require.Equal(t, filepath.Join(dir, "specs", "petstore.yaml"), pets.Filename)
+	uri, err := pets.URI()
+	require.NoError(t, err)
+	require.Equal(t, "file", uri.Scheme)
+	require.Equal(t, pets.Filename, uri.Path)
+
+	flags := pets.Flags()
+	require.Equal(t, filepath.Join(dir, "gen", "pets"), flags.OutputDir)
+	require.Equal(t, generator.TargetMock, flags.Target)
+	require.Equal(t, "example.com/pets", flags.ModuleName)
+	require.Equal(t, "fake", flags.PackageName)
+	require.Equal(t, "models", flags.DTOsPackageName)
+	require.False(t, flags.GenerateModule)
+	require.Equal(t, generator.OptionalStyleNullable, flags.OptionalStyle)
+	require.Equal(t, generator.OrderingSpec, flags.Ordering)
+	require.Equal(t, generator.GroupingTag, flags.Grouping)
+	require.Equal(t, []string{"schemas.example.com"}, flags.AllowedHosts)
+	require.Equal(t, map[string]string{"Money": "example.com/money.Amount"}, flags.TypeMappings)
+	require.Equal(t, map[string]string{"decimal": "example.com/money.Decimal"}, flags.FormatMappings)
+	require.Equal(t, map[string]string{"Pet": "Animal"}, flags.TypeNames)
+	require.Equal(t, map[string]string{"listPets": "All"}, flags.MethodNames)
+	require.Equal(t, []string{"pets"}, flags.Include.Tags)
+
+	remote := config.Specs[1]
+	require.Equal(t, "https://api.example.com/openapi.yaml", remote.Name)
+	uri, err = remote.URI()
+	require.NoError(t, err)
+	require.Equal(t, "api.example.com", uri.Host)
+	flags = remote.Flags()
+	defaults := generator.DefaultFlags()
+	require.Equal(t, "/abs/out", flags.OutputDir)
+	require.Equal(t, defaults.Target, flags.Target)
+	require.Equal(t, defaults.GenerateModule, flags.GenerateModule)
+	require.Equal(t, defaults.OptionalStyle, flags.OptionalStyle)
+	require.Equal(t, defaults.DTOsPackageName, flags.DTOsPackageName)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "no spec", content: "specs: []", err: "does not list any spec"},
+		{name: "unknown field", content: "specs:\n  - filename: a.yaml\n    output: out\n    outptu: typo", err: "field outptu not found"},
+		{name: "no location", content: "specs:\n  - output: out", err: "spec 0 must have either a filename or a url"},
+		{name: "both locations", content: "specs:\n  - filename: a.yaml\n    url: https://example.com/a.yaml\n    output: out", err: "spec 0 must have either a filename or a url"},
+		{name: "no output", content: "specs:\n  - filename: a.yaml", err: "spec a.yaml has no output directory"},
+		{name: "duplicate name", content: "specs:\n  - {name: a, filename: a.yaml, output: a}\n  - {name: a, filename: b.yaml, output: b}", err: "spec a is listed twice"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tc.content))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), DefaultConfigFile))
+	require.ErrorContains(t, err, "failed to read project file")
+}
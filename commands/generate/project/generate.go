@@ -0,0 +1,55 @@
+package project
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent-cli/commands/generate/oas3/client/generator"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/slices"
+)
+
+// Options apply to every spec of the project.
+type Options struct {
+	// Only restricts the generation to the named specs, unless it is empty.
+	Only []string
+	// Check compares the generated code with the one on disk instead of writing it.
+	Check bool
+	// DryRun lists the files that would change instead of writing them.
+	DryRun bool
+	// Diff prints the diff of the files listed by DryRun.
+	Diff bool
+}
+
+// Generate generates the code of every spec of the project. A failing spec does not prevent the other ones from being
+// generated, all the errors are reported at the end.
+func Generate(ctx context.Context, config *Config, opts Options) error {
+	log := logger.FromContext(ctx)
+	for _, name := range opts.Only {
+		if !slices.Contains(slices.Map(config.Specs, func(in Spec) string { return in.Name }), name) {
+			return errors.Newf("unknown spec %s", name)
+		}
+	}
+	var errs error
+	for _, spec := range config.Specs {
+		if len(opts.Only) > 0 && !slices.Contains(opts.Only, spec.Name) {
+			continue
+		}
+		log.Info("generating spec", zap.String("spec", spec.Name), zap.String("output", spec.Output))
+		uri, err := spec.URI()
+		if err == nil {
+			flags := spec.Flags()
+			flags.Check = opts.Check
+			flags.DryRun = opts.DryRun
+			flags.Diff = opts.Diff
+			err = generator.Generate(ctx, uri, flags)
+		}
+		if err != nil {
+			errs = multierr.Append(errs, errors.Wrapf(err, "failed to generate spec %s", spec.Name))
+		}
+	}
+	return errs
+}
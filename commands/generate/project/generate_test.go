@@ -0,0 +1,54 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const minimalSpec = `openapi: 3.0.3
+info: {title: minimal, version: "1"}
+paths:
+  /ping:
+    get:
+      operationId: ping
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: string}
+`
+
+func TestGenerate(t *testing.T) {
+	filename := writeConfig(t, `
+specs:
+  - name: good
+    filename: minimal.yaml
+    output: good
+    module: example.com/good
+    generateModule: false
+  - name: missing
+    filename: missing.yaml
+    output: missing
+    generateModule: false
+`)
+	dir := filepath.Dir(filename)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "minimal.yaml"), []byte(minimalSpec), 0644))
+	config, err := LoadConfig(filename)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = Generate(ctx, config, Options{Only: []string{"unknown"}})
+	require.EqualError(t, err, "unknown spec unknown")
+
+	err = Generate(ctx, config, Options{})
+	require.ErrorContains(t, err, "failed to generate spec missing")
+	require.NotContains(t, err.Error(), "failed to generate spec good")
+	require.FileExists(t, filepath.Join(dir, "good", "client.go"))
+
+	require.NoError(t, Generate(ctx, config, Options{Only: []string{"good"}, Check: true}))
+}
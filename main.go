@@ -1,10 +1,14 @@
 package main
 
 import (
+	"os"
+
 	"github.com/kiwiworks/rodent-cli/commands"
+	"github.com/kiwiworks/rodent-cli/commands/exitcode"
 	"github.com/kiwiworks/rodent/app"
 )
 
 func main() {
 	app.New("rodent-cli", "0.1.0", app.Modules(commands.Module)).Run()
+	os.Exit(exitcode.Code())
 }